@@ -0,0 +1,261 @@
+// Package audioinfo 解析合成音频的时长、采样率、声道数和码率，不依赖 ffprobe
+//
+// 支持 mp3（包括 Xing / Info / VBRI 信息帧）、wav、pcm 和 ogg_opus，与 audio.encoding 的取值一致
+//
+//	info, err := audioinfo.Probe(audio, "mp3", audioinfo.PCMFormat{})
+//	fmt.Println(info.Duration, info.SampleRate, info.Bitrate)
+package audioinfo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// 音频格式，与 audio.encoding 的取值一致
+const (
+	FormatMP3     = "mp3"
+	FormatWAV     = "wav"
+	FormatPCM     = "pcm"
+	FormatOggOpus = "ogg_opus"
+)
+
+// 服务端 pcm 的默认格式：24kHz、16 位、单声道
+const (
+	DefaultSampleRate = 24000
+	DefaultBitDepth   = 16
+	DefaultChannels   = 1
+)
+
+// Opus 的 granule 总是按 48kHz 计数
+const opusGranuleRate = 48000
+
+// ErrUnknownFormat 无法识别音频格式
+var ErrUnknownFormat = errors.New("audioinfo: unknown audio format")
+
+// Info 音频信息
+type Info struct {
+	Format     string        // mp3 / wav / pcm / ogg_opus
+	Duration   time.Duration // 播放时长
+	SampleRate int           // 采样率，ogg_opus 为 OpusHead 中记录的原始采样率
+	Channels   int           // 声道数
+	BitDepth   int           // 采样位数，只有 wav 和 pcm 有
+	Bitrate    int           // 平均码率，单位 bit/s
+	Bytes      int           // 音频数据的字节数，不包括标签和文件头
+}
+
+// PCMFormat pcm 没有文件头，需要由请求参数提供格式，零值字段使用默认值
+type PCMFormat struct {
+	SampleRate int // 默认为 24000，即 audio.rate
+	BitDepth   int // 默认为 16
+	Channels   int // 默认为 1
+}
+
+func (f PCMFormat) withDefaults() PCMFormat {
+	if f.SampleRate <= 0 {
+		f.SampleRate = DefaultSampleRate
+	}
+	if f.BitDepth <= 0 {
+		f.BitDepth = DefaultBitDepth
+	}
+	if f.Channels <= 0 {
+		f.Channels = DefaultChannels
+	}
+	return f
+}
+
+// Probe 按 format 解析音频，format 为空时根据文件头识别 mp3 / wav / ogg_opus
+// pcm 只在 format 为 pcm 时使用 pcm 的格式
+func Probe(b []byte, format string, pcm PCMFormat) (*Info, error) {
+	if format == "" {
+		format = Detect(b)
+	}
+	switch format {
+	case FormatMP3:
+		return ProbeMP3(b)
+	case FormatWAV:
+		return ProbeWAV(b)
+	case FormatPCM:
+		return ProbePCM(len(b), pcm)
+	case FormatOggOpus:
+		return ProbeOggOpus(b)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Detect 根据文件头识别格式，无法识别时返回空字符串
+func Detect(b []byte) string {
+	switch {
+	case len(b) >= 12 && string(b[0:4]) == "RIFF" && string(b[8:12]) == "WAVE":
+		return FormatWAV
+	case len(b) >= 4 && string(b[0:4]) == "OggS":
+		return FormatOggOpus
+	case len(b) >= 3 && string(b[0:3]) == "ID3":
+		return FormatMP3
+	case len(b) >= 2 && b[0] == 0xFF && b[1]&0xE0 == 0xE0:
+		return FormatMP3
+	}
+	return ""
+}
+
+// ProbeMP3 解析 mp3，存在 Xing / Info / VBRI 信息帧且记录了帧数时直接计算时长，否则遍历全部帧
+func ProbeMP3(b []byte) (*Info, error) {
+	end := len(b)
+	if end >= 128 && string(b[end-128:end-125]) == "TAG" {
+		end -= 128
+	}
+	pos := internal.Mp3ID3v2Size(b)
+	first, pos, err := syncMp3(b[:end], pos)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Format: FormatMP3, SampleRate: first.SampleRate, Channels: first.Channels}
+
+	if first.Size <= end-pos {
+		if h := internal.ParseMp3VbrHeader(b[pos:pos+first.Size], first); h != nil {
+			if h.Frames > 0 {
+				info.Bytes = h.Bytes
+				if info.Bytes == 0 {
+					info.Bytes = end - pos - first.Size
+				}
+				info.Duration = samplesDuration(int64(h.Frames)*int64(first.Samples), first.SampleRate)
+				info.Bitrate = bitrate(info.Bytes, info.Duration)
+				return info, nil
+			}
+			// 信息帧没有记录帧数，跳过后遍历音频帧
+			pos += first.Size
+		}
+	}
+
+	var samples int64
+	start := pos
+	for pos+4 <= end {
+		f, err := internal.ParseMp3Frame(b[pos:end])
+		if err != nil || f.Size > end-pos {
+			break
+		}
+		samples += int64(f.Samples)
+		pos += f.Size
+	}
+	if samples == 0 {
+		return nil, errors.New("audioinfo: no complete mp3 frame")
+	}
+	info.Bytes = pos - start
+	info.Duration = samplesDuration(samples, first.SampleRate)
+	info.Bitrate = bitrate(info.Bytes, info.Duration)
+	return info, nil
+}
+
+// syncMp3 从 pos 开始查找第一个有效的帧头部
+func syncMp3(b []byte, pos int) (*internal.Mp3Frame, int, error) {
+	for ; pos+4 <= len(b); pos++ {
+		if b[pos] != 0xFF {
+			continue
+		}
+		if f, err := internal.ParseMp3Frame(b[pos:]); err == nil {
+			return f, pos, nil
+		}
+	}
+	return nil, 0, errors.New("audioinfo: no mp3 frame found")
+}
+
+// ProbeWAV 解析 wav 的 fmt 和 data 块，流式合成的 data 块长度未知时取到文件末尾
+func ProbeWAV(b []byte) (*Info, error) {
+	format, data, err := internal.ParseWav(b)
+	if err != nil {
+		return nil, fmt.Errorf("audioinfo: %w", err)
+	}
+	info := &Info{
+		Format:     FormatWAV,
+		SampleRate: int(format.SampleRate),
+		Channels:   int(format.Channels),
+		BitDepth:   int(format.BitsPerSample),
+		Bytes:      len(data),
+	}
+	byteRate := int(format.ByteRate)
+	if byteRate == 0 {
+		byteRate = info.SampleRate * info.Channels * info.BitDepth / 8
+	}
+	if byteRate == 0 {
+		return nil, errors.New("audioinfo: invalid wav: zero byte rate")
+	}
+	info.Duration = time.Duration(int64(len(data)) * int64(time.Second) / int64(byteRate))
+	info.Bitrate = byteRate * 8
+	return info, nil
+}
+
+// ProbePCM 根据数据长度和格式计算 pcm 的时长
+func ProbePCM(size int, format PCMFormat) (*Info, error) {
+	format = format.withDefaults()
+	if format.BitDepth%8 != 0 {
+		return nil, fmt.Errorf("audioinfo: unsupported pcm bit depth %d", format.BitDepth)
+	}
+	byteRate := format.SampleRate * format.Channels * format.BitDepth / 8
+	return &Info{
+		Format:     FormatPCM,
+		Duration:   time.Duration(int64(size) * int64(time.Second) / int64(byteRate)),
+		SampleRate: format.SampleRate,
+		Channels:   format.Channels,
+		BitDepth:   format.BitDepth,
+		Bitrate:    byteRate * 8,
+		Bytes:      size,
+	}, nil
+}
+
+// ProbeOggOpus 解析 Ogg Opus，时长为最后一个 granule 减去 pre-skip，按 48kHz 计算
+func ProbeOggOpus(b []byte) (*Info, error) {
+	pages, err := internal.ParseOggPages(b)
+	if err != nil {
+		return nil, fmt.Errorf("audioinfo: %w", err)
+	}
+	if len(pages) == 0 {
+		return nil, errors.New("audioinfo: invalid ogg: no pages")
+	}
+	channels, preSkip, ok := internal.OpusHead(pages[0])
+	if !ok {
+		return nil, errors.New("audioinfo: invalid ogg: missing OpusHead")
+	}
+
+	var granule int64
+	bytes := 0
+	for _, p := range pages {
+		if p.Granule > granule {
+			granule = p.Granule
+		}
+		// 头部页面的 granule 为0，不计入音频数据
+		if p.Granule != 0 {
+			bytes += len(p.Data)
+		}
+	}
+	samples := granule - int64(preSkip)
+	if samples < 0 {
+		samples = 0
+	}
+
+	info := &Info{
+		Format:     FormatOggOpus,
+		Duration:   samplesDuration(samples, opusGranuleRate),
+		SampleRate: internal.OpusInputRate(pages[0]),
+		Channels:   channels,
+		Bytes:      bytes,
+	}
+	if info.SampleRate == 0 {
+		info.SampleRate = opusGranuleRate
+	}
+	info.Bitrate = bitrate(bytes, info.Duration)
+	return info, nil
+}
+
+func samplesDuration(samples int64, rate int) time.Duration {
+	return time.Duration(samples * int64(time.Second) / int64(rate))
+}
+
+// bitrate 平均码率，时长为0时返回0
+func bitrate(bytes int, d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(int64(bytes) * 8 * int64(time.Second) / int64(d))
+}
@@ -0,0 +1,174 @@
+package audioinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// 128kbps 44100Hz 单声道 MPEG1 Layer III 帧，每帧 417 字节 1152 个采样
+const mp3FrameSize = 417
+
+func mp3Frame(payload string) []byte {
+	frame := make([]byte, mp3FrameSize)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	copy(frame[4+17:], payload)
+	return frame
+}
+
+func mp3Frames(n int) []byte {
+	return bytes.Repeat(mp3Frame(""), n)
+}
+
+func frameDuration(n int) time.Duration {
+	return time.Duration(int64(n) * 1152 * int64(time.Second) / 44100)
+}
+
+func TestProbeMP3(t *testing.T) {
+	id3 := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 2, 0, 0}
+	b := append(append([]byte{}, id3...), mp3Frames(100)...)
+	b = append(b, append([]byte("TAG"), make([]byte, 125)...)...)
+
+	info, err := Probe(b, "", PCMFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != FormatMP3 || info.SampleRate != 44100 || info.Channels != 1 || info.Bytes != 100*mp3FrameSize {
+		t.Errorf("info = %+v", info)
+	}
+	if info.Duration != frameDuration(100) {
+		t.Errorf("Duration = %v, want %v", info.Duration, frameDuration(100))
+	}
+	// 帧长度包含填充取整，平均码率接近 128kbps
+	if info.Bitrate < 127000 || info.Bitrate > 129000 {
+		t.Errorf("Bitrate = %d", info.Bitrate)
+	}
+
+	// 截断的最后一帧不计入
+	info, err = ProbeMP3(b[len(id3) : len(id3)+10*mp3FrameSize+100])
+	if err != nil || info.Duration != frameDuration(10) {
+		t.Errorf("truncated info = %+v, %v", info, err)
+	}
+
+	if _, err := ProbeMP3([]byte("not mp3")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestProbeMP3VbrHeader(t *testing.T) {
+	// Xing 记录 1000 帧，实际只有信息帧和两个音频帧
+	xing := mp3Frame("Xing\x00\x00\x00\x03\x00\x00\x03\xe8\x00\x06\x5d\x10")
+	info, err := ProbeMP3(append(xing, mp3Frames(2)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != frameDuration(1000) || info.Bytes != 0x065d10 {
+		t.Errorf("Xing info = %+v", info)
+	}
+
+	// VBRI 位于帧头部之后 32 字节
+	vbri := make([]byte, mp3FrameSize)
+	copy(vbri, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	copy(vbri[36:], "VBRI")
+	binary.BigEndian.PutUint32(vbri[36+10:], 5000)
+	binary.BigEndian.PutUint32(vbri[36+14:], 10)
+	info, err = ProbeMP3(vbri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != frameDuration(10) || info.Bytes != 5000 {
+		t.Errorf("VBRI info = %+v", info)
+	}
+
+	// 没有记录帧数的信息帧被跳过，遍历音频帧
+	info, err = ProbeMP3(append(mp3Frame("Info\x00\x00\x00\x00"), mp3Frames(3)...))
+	if err != nil || info.Duration != frameDuration(3) {
+		t.Errorf("Info without frames = %+v, %v", info, err)
+	}
+}
+
+func wav(rate uint32, channels, bits uint16, data []byte) []byte {
+	f := &internal.WavFormat{
+		AudioFormat:   1,
+		Channels:      channels,
+		SampleRate:    rate,
+		ByteRate:      rate * uint32(channels) * uint32(bits) / 8,
+		BlockAlign:    channels * bits / 8,
+		BitsPerSample: bits,
+	}
+	return append(internal.WavHeader(f, uint32(len(data))), data...)
+}
+
+func TestProbeWAV(t *testing.T) {
+	info, err := Probe(wav(16000, 1, 16, make([]byte, 16000)), "", PCMFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Info{Format: FormatWAV, Duration: 500 * time.Millisecond, SampleRate: 16000, Channels: 1, BitDepth: 16, Bitrate: 256000, Bytes: 16000}
+	if *info != want {
+		t.Errorf("info = %+v, want %+v", *info, want)
+	}
+
+	// 流式合成的 data 块长度未知
+	b := wav(24000, 1, 16, make([]byte, 48000))
+	binary.LittleEndian.PutUint32(b[40:44], 0xFFFFFFFF)
+	if info, err := ProbeWAV(b); err != nil || info.Duration != time.Second {
+		t.Errorf("streaming info = %+v, %v", info, err)
+	}
+
+	if _, err := ProbeWAV([]byte("RIFF")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestProbePCM(t *testing.T) {
+	info, err := Probe(make([]byte, 48000), FormatPCM, PCMFormat{})
+	if err != nil || info.Duration != time.Second || info.SampleRate != DefaultSampleRate || info.BitDepth != 16 || info.Bitrate != 384000 {
+		t.Errorf("default info = %+v, %v", info, err)
+	}
+	info, err = ProbePCM(32000, PCMFormat{SampleRate: 8000, Channels: 2})
+	if err != nil || info.Duration != time.Second || info.Channels != 2 {
+		t.Errorf("info = %+v, %v", info, err)
+	}
+	if _, err := ProbePCM(100, PCMFormat{BitDepth: 12}); err == nil {
+		t.Error("expected error for bit depth 12")
+	}
+	// pcm 没有文件头，无法自动识别
+	if _, err := Probe(make([]byte, 100), "", PCMFormat{}); err != ErrUnknownFormat {
+		t.Errorf("err = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func oggPage(headerType uint8, granule int64, seq uint32, data []byte) []byte {
+	p := &internal.OggPage{HeaderType: headerType, Granule: granule, Serial: 1, Sequence: seq, Segments: []byte{byte(len(data))}, Data: data}
+	return p.Bytes()
+}
+
+func TestProbeOggOpus(t *testing.T) {
+	head := []byte("OpusHead")
+	head = append(head, 1, 2, 0x38, 0x01) // 2 声道，pre-skip 312
+	head = append(head, 0xC0, 0x5D, 0, 0) // 原始采样率 24000
+	head = append(head, 0, 0, 0)
+
+	var b []byte
+	b = append(b, oggPage(internal.OggFlagBOS, 0, 0, head)...)
+	b = append(b, oggPage(0, 0, 1, []byte("OpusTags"))...)
+	b = append(b, oggPage(0, 24312, 2, make([]byte, 100))...)
+	b = append(b, oggPage(internal.OggFlagEOS, 48312, 3, make([]byte, 100))...)
+
+	info, err := Probe(b, "", PCMFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Info{Format: FormatOggOpus, Duration: time.Second, SampleRate: 24000, Channels: 2, Bitrate: 1600, Bytes: 200}
+	if *info != want {
+		t.Errorf("info = %+v, want %+v", *info, want)
+	}
+
+	if _, err := ProbeOggOpus(oggPage(0, 0, 0, []byte("OpusTags"))); err == nil {
+		t.Error("expected error for missing OpusHead")
+	}
+}
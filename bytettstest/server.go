@@ -0,0 +1,570 @@
+// Package bytettstest 提供本地模拟的字节语音合成服务，用于不依赖网络和凭证的测试
+//
+// 模拟服务实现短文本合成 /api/v1/tts，以及长文本的 submit / query 接口（包括情感预测版），
+// 可以按顺序预设响应、返回码、延迟和任务状态变化，并记录收到的全部请求
+//
+//	srv := bytettstest.NewServer()
+//	defer srv.Close()
+//	tts, _ := byteTts.NewGoTTS(ctx,
+//		byteTts.WithAppId("appid"), byteTts.WithCluster("cluster"), byteTts.WithToken("token"),
+//		byteTts.WithBaseURL(srv.URL),
+//	)
+package bytettstest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// 模拟的接口路径，与线上服务一致
+const (
+	PathTTS           = "/api/v1/tts"
+	PathSubmit        = "/api/v1/tts_async/submit"
+	PathQuery         = "/api/v1/tts_async/query"
+	PathEmotionSubmit = "/api/v1/tts_async_with_emotion/submit"
+	PathEmotionQuery  = "/api/v1/tts_async_with_emotion/query"
+	// 长文本任务音频的下载地址前缀，后面是任务标识
+	PathAudioPrefix = "/audio/"
+)
+
+const (
+	codeTTSSuccess   = 3000
+	codeAsyncSuccess = 0
+
+	// wav 音频默认的采样率
+	defaultSampleRate = 24000
+)
+
+// 长文本任务状态，与 task_status 字段一致
+const (
+	TaskRunning = 0
+	TaskSuccess = 1
+	TaskFailure = 2
+)
+
+// Response 预设的接口响应
+type Response struct {
+	Status   int           // HTTP 状态码，默认 200
+	Code     int           // 接口返回码，为0时短文本返回 3000，长文本返回 0
+	Message  string        // 返回信息
+	Audio    []byte        // 短文本返回的音频，为 nil 时返回请求文本的字节，encoding 为 wav 时带有 16 位单声道的 WAV 头部
+	Duration string        // 短文本 addition.duration，单位毫秒
+	Frontend string        // 短文本 addition.frontend，为空且请求设置 with_frontend 时按每字 100 毫秒生成
+	Latency  time.Duration // 返回前的等待时间，客户端取消后立即结束
+	Body     []byte        // 原始响应体，设置后忽略 Code、Message、Audio
+}
+
+// Task 长文本任务的脚本
+type Task struct {
+	// Statuses 每次查询依次返回的任务状态，用完后保持最后一个状态，为空时直接返回成功
+	Statuses []int
+	// Audio 合成成功后 audio_url 返回的音频，为 nil 时返回请求文本的字节
+	Audio []byte
+	// Message 任务失败时的信息
+	Message string
+	// ExpireTime 音频URL的过期时间，为零值时为1小时后
+	ExpireTime time.Time
+}
+
+// Request 服务端收到的请求
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Query  url.Values
+	Body   []byte
+	Time   time.Time
+}
+
+// JSON 将请求体解析到 v
+func (r Request) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Text 短文本请求的 request.text 或长文本请求的 text
+func (r Request) Text() string {
+	var body struct {
+		Text    string `json:"text"`
+		Request struct {
+			Text string `json:"text"`
+		} `json:"request"`
+	}
+	_ = json.Unmarshal(r.Body, &body)
+	if body.Request.Text != "" {
+		return body.Request.Text
+	}
+	return body.Text
+}
+
+// taskState 服务端保存的任务
+type taskState struct {
+	Task
+	id      string
+	text    string
+	queries int
+}
+
+// Server 本地模拟的语音合成服务，所有方法可以并发调用
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	token    string
+	latency  time.Duration
+	tts      []Response
+	submit   []Response
+	query    []Response
+	scripts  []Task
+	tasks    map[string]*taskState
+	nextID   int
+	conns    int
+}
+
+// NewServer 创建并启动模拟服务，使用完毕后调用 Close
+func NewServer() *Server {
+	s := &Server{tasks: make(map[string]*taskState)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathTTS, s.handleTTS)
+	mux.HandleFunc(PathSubmit, s.handleSubmit)
+	mux.HandleFunc(PathEmotionSubmit, s.handleSubmit)
+	mux.HandleFunc(PathQuery, s.handleQuery)
+	mux.HandleFunc(PathEmotionQuery, s.handleQuery)
+	mux.HandleFunc(PathAudioPrefix, s.handleAudio)
+	s.Server = httptest.NewUnstartedServer(mux)
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+		}
+	}
+	s.Start()
+	return s
+}
+
+// SetToken 要求请求携带 Authorization: Bearer;token，否则返回 401，默认不校验
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetLatency 设置所有接口的基础延迟
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// EnqueueTTS 预设之后的短文本合成响应，按顺序使用，用完后返回成功
+func (s *Server) EnqueueTTS(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tts = append(s.tts, responses...)
+}
+
+// EnqueueSubmit 预设之后的长文本任务创建响应，按顺序使用，用完后正常创建任务
+// Code 为0且 Status 为200的响应仍会创建任务
+func (s *Server) EnqueueSubmit(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.submit = append(s.submit, responses...)
+}
+
+// EnqueueQuery 预设之后的长文本任务查询响应，按顺序使用，用完后按任务脚本返回
+// Code 为0且 Status 为200的响应仍按任务脚本返回
+func (s *Server) EnqueueQuery(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.query = append(s.query, responses...)
+}
+
+// EnqueueTask 预设之后创建的长文本任务的脚本，按顺序使用，用完后任务直接成功
+func (s *Server) EnqueueTask(tasks ...Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts = append(s.scripts, tasks...)
+}
+
+// Requests 收到的全部请求
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo 发送到 path 的请求
+func (s *Server) RequestsTo(path string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Request
+	for _, r := range s.requests {
+		if r.Path == path {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Conns 新建的连接数，用于检查客户端是否复用连接
+func (s *Server) Conns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+// TaskIDs 已创建的任务标识，按创建顺序排列
+func (s *Server) TaskIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, s.nextID)
+	for i := 1; i <= s.nextID; i++ {
+		ids = append(ids, taskID(i))
+	}
+	return ids
+}
+
+// Reset 清空请求记录、预设响应和任务
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.tts, s.submit, s.query, s.scripts = nil, nil, nil, nil
+	s.tasks = make(map[string]*taskState)
+	s.nextID = 0
+}
+
+func taskID(n int) string {
+	return fmt.Sprintf("task-%d", n)
+}
+
+// record 记录请求并校验令牌，令牌错误时已写入响应并返回 false
+func (s *Server) record(w http.ResponseWriter, r *http.Request) (Request, bool) {
+	body, _ := io.ReadAll(r.Body)
+	req := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Query:  r.URL.Query(),
+		Body:   body,
+		Time:   time.Now(),
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	token, latency := s.token, s.latency
+	s.mu.Unlock()
+
+	if !sleep(r, latency) {
+		return req, false
+	}
+	if token != "" && r.Header.Get("Authorization") != "Bearer;"+token {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"code": 3001, "message": "invalid token"})
+		return req, false
+	}
+	return req, true
+}
+
+// sleep 等待 d，客户端取消时返回 false
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// next 取出队列中的第一个响应
+func next(queue *[]Response) (Response, bool) {
+	if len(*queue) == 0 {
+		return Response{}, false
+	}
+	resp := (*queue)[0]
+	*queue = (*queue)[1:]
+	return resp, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeScripted 等待预设的延迟后写入响应，客户端取消时不写入
+func writeScripted(w http.ResponseWriter, r *http.Request, resp Response, body map[string]any) {
+	if !sleep(r, resp.Latency) {
+		return
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if resp.Body != nil {
+		w.WriteHeader(status)
+		_, _ = w.Write(resp.Body)
+		return
+	}
+	writeJSON(w, status, body)
+}
+
+func (s *Server) handleTTS(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.record(w, r)
+	if !ok {
+		return
+	}
+	var params struct {
+		Audio struct {
+			Encoding string `json:"encoding"`
+			Rate     int    `json:"rate"`
+		} `json:"audio"`
+		Request struct {
+			Reqid        string `json:"reqid"`
+			Text         string `json:"text"`
+			WithFrontend int    `json:"with_frontend"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(req.Body, &params); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": 3001, "message": "invalid json: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	resp, _ := next(&s.tts)
+	s.mu.Unlock()
+
+	body := map[string]any{
+		"reqid":     params.Request.Reqid,
+		"operation": "query",
+	}
+	if resp.Code != 0 && resp.Code != codeTTSSuccess {
+		body["code"] = resp.Code
+		body["Message"] = resp.Message
+	} else {
+		audio := resp.Audio
+		if audio == nil {
+			audio = []byte(params.Request.Text)
+			if params.Audio.Encoding == "wav" {
+				audio = append(wavHeader(params.Audio.Rate, len(audio)), audio...)
+			}
+		}
+		body["code"] = codeTTSSuccess
+		body["Message"] = "Success"
+		body["sequence"] = -1
+		body["data"] = base64.StdEncoding.EncodeToString(audio)
+		addition := map[string]any{}
+		if resp.Duration != "" {
+			addition["duration"] = resp.Duration
+		}
+		if resp.Frontend != "" {
+			addition["frontend"] = resp.Frontend
+		} else if params.Request.WithFrontend == 1 {
+			frontend, duration := defaultFrontend(params.Request.Text)
+			addition["frontend"] = frontend
+			if resp.Duration == "" {
+				addition["duration"] = strconv.FormatInt(duration.Milliseconds(), 10)
+			}
+		}
+		if len(addition) > 0 {
+			body["addition"] = addition
+		}
+	}
+	writeScripted(w, r, resp, body)
+}
+
+// wavHeader 16 位单声道的 WAV 头部，rate 为0时使用 24000
+func wavHeader(rate, dataSize int) []byte {
+	if rate == 0 {
+		rate = defaultSampleRate
+	}
+	return internal.WavHeader(&internal.WavFormat{
+		AudioFormat:   1,
+		Channels:      1,
+		SampleRate:    uint32(rate),
+		ByteRate:      uint32(rate * 2),
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}, uint32(dataSize))
+}
+
+// FrontendWordDuration 默认生成的前端信息中每个字的时长
+const FrontendWordDuration = 100 * time.Millisecond
+
+// defaultFrontend 生成前端信息，中文按字、英文按单词，每个字词 FrontendWordDuration，返回音频总时长
+func defaultFrontend(text string) (string, time.Duration) {
+	type word struct {
+		Word      string  `json:"word"`
+		StartTime float64 `json:"start_time"`
+		EndTime   float64 `json:"end_time"`
+	}
+	var words []word
+	var at time.Duration
+	add := func(w string) {
+		words = append(words, word{Word: w, StartTime: at.Seconds(), EndTime: (at + FrontendWordDuration).Seconds()})
+		at += FrontendWordDuration
+	}
+	for _, field := range strings.Fields(text) {
+		start := -1
+		for i, r := range field {
+			if r < utf8.RuneSelf {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start >= 0 {
+				add(field[start:i])
+				start = -1
+			}
+			add(string(r))
+		}
+		if start >= 0 {
+			add(field[start:])
+		}
+	}
+	b, _ := json.Marshal(map[string]any{"words": words, "phonemes": []any{}})
+	return string(b), at
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.record(w, r)
+	if !ok {
+		return
+	}
+	var params struct {
+		Reqid string `json:"reqid"`
+		Text  string `json:"text"`
+	}
+	if err := json.Unmarshal(req.Body, &params); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": 40000, "message": "invalid json: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	resp, _ := next(&s.submit)
+	if resp.Code != codeAsyncSuccess || (resp.Status != 0 && resp.Status != http.StatusOK) {
+		s.mu.Unlock()
+		writeScripted(w, r, resp, map[string]any{"reqid": params.Reqid, "code": resp.Code, "message": resp.Message})
+		return
+	}
+	s.nextID++
+	task := &taskState{id: taskID(s.nextID), text: params.Text}
+	if len(s.scripts) > 0 {
+		task.Task = s.scripts[0]
+		s.scripts = s.scripts[1:]
+	}
+	s.tasks[task.id] = task
+	s.mu.Unlock()
+
+	writeScripted(w, r, resp, map[string]any{
+		"reqid":       params.Reqid,
+		"code":        codeAsyncSuccess,
+		"message":     "Success",
+		"task_id":     task.id,
+		"task_status": TaskRunning,
+		"text_length": len([]rune(params.Text)),
+	})
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.record(w, r)
+	if !ok {
+		return
+	}
+	id := req.Query.Get("task_id")
+
+	s.mu.Lock()
+	resp, _ := next(&s.query)
+	if resp.Code != codeAsyncSuccess || (resp.Status != 0 && resp.Status != http.StatusOK) {
+		s.mu.Unlock()
+		writeScripted(w, r, resp, map[string]any{"task_id": id, "code": resp.Code, "message": resp.Message})
+		return
+	}
+	task, ok := s.tasks[id]
+	if !ok {
+		s.mu.Unlock()
+		writeScripted(w, r, resp, map[string]any{"task_id": id, "code": 40400, "message": "task not found"})
+		return
+	}
+	status := TaskSuccess
+	if n := len(task.Statuses); n > 0 {
+		idx := task.queries
+		if idx >= n {
+			idx = n - 1
+		}
+		status = task.Statuses[idx]
+	}
+	task.queries++
+	body := map[string]any{
+		"code":        codeAsyncSuccess,
+		"message":     "Success",
+		"task_id":     id,
+		"task_status": status,
+		"text_length": len([]rune(task.text)),
+	}
+	switch status {
+	case TaskSuccess:
+		expire := task.ExpireTime
+		if expire.IsZero() {
+			expire = time.Now().Add(time.Hour)
+		}
+		body["audio_url"] = s.URL + PathAudioPrefix + id
+		body["url_expire_time"] = expire.Unix()
+	case TaskFailure:
+		body["message"] = task.Message
+	}
+	s.mu.Unlock()
+
+	writeScripted(w, r, resp, body)
+}
+
+// handleAudio 返回任务的音频，支持 Range 请求，过期后返回 403
+func (s *Server) handleAudio(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.record(w, r); !ok {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, PathAudioPrefix)
+
+	s.mu.Lock()
+	task, ok := s.tasks[id]
+	var audio []byte
+	var expired bool
+	if ok {
+		audio = task.Audio
+		if audio == nil {
+			audio = []byte(task.text)
+		}
+		expired = !task.ExpireTime.IsZero() && time.Now().After(task.ExpireTime)
+	}
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		http.NotFound(w, r)
+	case expired:
+		http.Error(w, "url expired", http.StatusForbidden)
+	default:
+		w.Header().Set("Content-Type", "audio/mpeg")
+		http.ServeContent(w, r, id, time.Time{}, bytes.NewReader(audio))
+	}
+}
@@ -0,0 +1,226 @@
+package bytettstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func postJSON(t *testing.T, url string, body any, header map[string]string) (*http.Response, map[string]any) {
+	b, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post err = %v", err)
+	}
+	defer resp.Body.Close()
+	var res map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&res)
+	return resp, res
+}
+
+func getJSON(t *testing.T, url string) map[string]any {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("get err = %v", err)
+	}
+	defer resp.Body.Close()
+	var res map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&res)
+	return res
+}
+
+func ttsBody(text string) map[string]any {
+	return map[string]any{"request": map[string]any{"reqid": "reqid", "text": text}}
+}
+
+func TestServerTTS(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(
+		Response{Code: 3005, Message: "busy"},
+		Response{Audio: []byte("audio"), Duration: "100"},
+	)
+
+	_, res := postJSON(t, srv.URL+PathTTS, ttsBody("你好"), nil)
+	if res["code"] != float64(3005) || res["Message"] != "busy" {
+		t.Errorf("scripted error response = %v", res)
+	}
+
+	_, res = postJSON(t, srv.URL+PathTTS, ttsBody("你好"), nil)
+	if data, _ := base64.StdEncoding.DecodeString(res["data"].(string)); string(data) != "audio" {
+		t.Errorf("scripted audio = %q", data)
+	}
+	if res["addition"].(map[string]any)["duration"] != "100" {
+		t.Errorf("addition = %v", res["addition"])
+	}
+
+	// 预设用完后返回请求文本
+	_, res = postJSON(t, srv.URL+PathTTS, ttsBody("你好"), nil)
+	if data, _ := base64.StdEncoding.DecodeString(res["data"].(string)); string(data) != "你好" || res["code"] != float64(3000) {
+		t.Errorf("default response = %v", res)
+	}
+
+	reqs := srv.RequestsTo(PathTTS)
+	if len(reqs) != 3 || reqs[0].Text() != "你好" || reqs[0].Method != http.MethodPost {
+		t.Errorf("requests = %+v", reqs)
+	}
+}
+
+func TestServerTTSWav(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	// wav 格式回显的文本带有 WAV 头部，采样率与请求一致
+	body := ttsBody("你好")
+	body["audio"] = map[string]any{"encoding": "wav", "rate": 16000}
+	_, res := postJSON(t, srv.URL+PathTTS, body, nil)
+	data, _ := base64.StdEncoding.DecodeString(res["data"].(string))
+	if len(data) != 44+len("你好") || string(data[:4]) != "RIFF" || string(data[44:]) != "你好" {
+		t.Fatalf("wav audio = %q", data)
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != 16000 {
+		t.Errorf("sample rate = %d, want 16000", rate)
+	}
+}
+
+func TestServerToken(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetToken("token")
+
+	resp, _ := postJSON(t, srv.URL+PathTTS, ttsBody("你好"), nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+	resp, _ = postJSON(t, srv.URL+PathTTS, ttsBody("你好"), map[string]string{"Authorization": "Bearer;token"})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(Response{Latency: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	b, _ := json.Marshal(ttsBody("你好"))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+PathTTS, bytes.NewReader(b))
+	start := time.Now()
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Fatal("expected deadline error")
+	}
+	if d := time.Since(start); d > time.Millisecond*500 {
+		t.Errorf("request took %v", d)
+	}
+}
+
+func TestServerTaskTransitions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.EnqueueTask(
+		Task{Statuses: []int{TaskRunning, TaskRunning, TaskSuccess}, Audio: []byte("long audio")},
+		Task{Statuses: []int{TaskRunning, TaskFailure}, Message: "synthesis failed"},
+	)
+	srv.EnqueueQuery(Response{Code: 50000, Message: "internal error"})
+
+	_, res := postJSON(t, srv.URL+PathSubmit, map[string]any{"reqid": "r1", "text": "长文本"}, nil)
+	id := res["task_id"].(string)
+	_, res = postJSON(t, srv.URL+PathEmotionSubmit, map[string]any{"reqid": "r2", "text": "长文本"}, nil)
+	failedID := res["task_id"].(string)
+	if ids := srv.TaskIDs(); len(ids) != 2 || ids[0] != id || ids[1] != failedID {
+		t.Fatalf("TaskIDs = %v", ids)
+	}
+
+	if res := getJSON(t, srv.URL+PathQuery+"?task_id="+id); res["code"] != float64(50000) {
+		t.Errorf("scripted query error = %v", res)
+	}
+	var statuses []float64
+	var audioURL string
+	for i := 0; i < 4; i++ {
+		res := getJSON(t, srv.URL+PathQuery+"?task_id="+id)
+		statuses = append(statuses, res["task_status"].(float64))
+		audioURL, _ = res["audio_url"].(string)
+	}
+	if want := []float64{0, 0, 1, 1}; !equalFloats(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+
+	resp, err := http.Get(audioURL)
+	if err != nil {
+		t.Fatalf("download err = %v", err)
+	}
+	audio, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(audio) != "long audio" || resp.Header.Get("Content-Type") != "audio/mpeg" {
+		t.Errorf("audio = %q, Content-Type = %s", audio, resp.Header.Get("Content-Type"))
+	}
+
+	getJSON(t, srv.URL+PathEmotionQuery+"?task_id="+failedID)
+	res = getJSON(t, srv.URL+PathEmotionQuery+"?task_id="+failedID)
+	if res["task_status"] != float64(TaskFailure) || res["message"] != "synthesis failed" {
+		t.Errorf("failed task = %v", res)
+	}
+
+	if res := getJSON(t, srv.URL+PathQuery+"?task_id=unknown"); res["code"] != float64(40400) {
+		t.Errorf("unknown task = %v", res)
+	}
+}
+
+func TestServerAudioExpired(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.EnqueueTask(Task{ExpireTime: time.Now().Add(-time.Minute)})
+
+	_, res := postJSON(t, srv.URL+PathSubmit, map[string]any{"text": "长文本"}, nil)
+	res = getJSON(t, srv.URL+PathQuery+"?task_id="+res["task_id"].(string))
+	resp, err := http.Get(res["audio_url"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestServerReset(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(Response{Code: 3050})
+	postJSON(t, srv.URL+PathSubmit, map[string]any{"text": "长文本"}, nil)
+	srv.Reset()
+
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("requests after Reset = %d", n)
+	}
+	if ids := srv.TaskIDs(); len(ids) != 0 {
+		t.Errorf("tasks after Reset = %v", ids)
+	}
+	if _, res := postJSON(t, srv.URL+PathTTS, ttsBody("你好"), nil); res["code"] != float64(3000) {
+		t.Errorf("response after Reset = %v", res)
+	}
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package go_byte_tts_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func newFakeTTS(t *testing.T, srv *bytettstest.Server, opts ...byteTts.Option) byteTts.GoTTSInter {
+	opts = append([]byteTts.Option{
+		byteTts.WithAppId("appid"),
+		byteTts.WithCluster("cluster"),
+		byteTts.WithToken("token"),
+		byteTts.WithBaseURL(srv.URL),
+	}, opts...)
+	tts, err := byteTts.NewGoTTS(context.TODO(), opts...)
+	if err != nil {
+		t.Fatalf("NewGoTTS err = %v", err)
+	}
+	return tts
+}
+
+func newFakeRequest(text string) *byteTts.SynthesisRequest {
+	return &byteTts.SynthesisRequest{
+		Audio:   byteTts.AudioConfig{VoiceType: "BV406_V2_streaming", Encoding: "mp3"},
+		Request: byteTts.RequestConfig{Text: text},
+	}
+}
+
+func TestFakeServerSynthesize(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.SetToken("token")
+	srv.EnqueueTTS(bytettstest.Response{Audio: []byte("audio"), Duration: "1500"})
+	tts := newFakeTTS(t, srv)
+
+	result, err := tts.Synthesize(context.Background(), newFakeRequest("你好"))
+	if err != nil {
+		t.Fatalf("Synthesize err = %v", err)
+	}
+	if string(result.Audio) != "audio" || result.Duration != time.Millisecond*1500 {
+		t.Errorf("result = %+v", result)
+	}
+
+	reqs := srv.RequestsTo(bytettstest.PathTTS)
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %d, want 1", len(reqs))
+	}
+	var body map[string]map[string]any
+	if err := reqs[0].JSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body["app"]["appid"] != "appid" || body["app"]["cluster"] != "cluster" || body["request"]["reqid"] != result.ReqID {
+		t.Errorf("request body = %v", body)
+	}
+}
+
+func TestFakeServerRetry(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(
+		bytettstest.Response{Code: 3005, Message: "server busy"},
+		bytettstest.Response{Status: 500, Code: 3031, Message: "internal error"},
+	)
+	policy := byteTts.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	tts := newFakeTTS(t, srv, byteTts.WithRetryPolicy(policy))
+
+	result, err := tts.Synthesize(context.Background(), newFakeRequest("你好"))
+	if err != nil {
+		t.Fatalf("Synthesize err = %v", err)
+	}
+	if string(result.Audio) != "你好" {
+		t.Errorf("audio = %q", result.Audio)
+	}
+	reqs := srv.RequestsTo(bytettstest.PathTTS)
+	if len(reqs) != 3 {
+		t.Fatalf("requests = %d, want 3", len(reqs))
+	}
+	var first, last map[string]map[string]any
+	_ = reqs[0].JSON(&first)
+	_ = reqs[2].JSON(&last)
+	if first["request"]["reqid"] == last["request"]["reqid"] {
+		t.Error("reqid should change on retry")
+	}
+
+	srv.Reset()
+	srv.EnqueueTTS(bytettstest.Response{Code: 3005, Message: "server busy"})
+	_, err = newFakeTTS(t, srv).Synthesize(context.Background(), newFakeRequest("你好"))
+	if !errors.Is(err, byteTts.ErrServerBusy) {
+		t.Errorf("err = %v, want ErrServerBusy", err)
+	}
+}
+
+func TestFakeServerLongText(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTask(bytettstest.Task{
+		Statuses: []int{bytettstest.TaskRunning, bytettstest.TaskRunning, bytettstest.TaskSuccess},
+		Audio:    []byte("long audio"),
+	})
+	tts := newFakeTTS(t, srv)
+
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "长文本", "voice_type": "BV701_streaming"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	var polls int
+	result, err := tts.WaitForLongTextTask(context.Background(), created.TaskId, byteTts.WaitOptions{
+		PollInterval: time.Millisecond,
+		OnProgress:   func(*byteTts.TtsAsyncQueryRep) { polls++ },
+	})
+	if err != nil {
+		t.Fatalf("WaitForLongTextTask err = %v", err)
+	}
+	if polls != 3 || result.TaskStatus != byteTts.TaskStatus(bytettstest.TaskSuccess) {
+		t.Errorf("polls = %d, status = %d", polls, result.TaskStatus)
+	}
+
+	var buf bytes.Buffer
+	if err := tts.DownloadLongTextAudio(context.Background(), result, &buf); err != nil {
+		t.Fatalf("DownloadLongTextAudio err = %v", err)
+	}
+	if buf.String() != "long audio" {
+		t.Errorf("audio = %q", buf.String())
+	}
+	if n := len(srv.RequestsTo(bytettstest.PathQuery)); n != 3 {
+		t.Errorf("query requests = %d, want 3", n)
+	}
+}
+
+func TestFakeServerTaskFailure(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTask(bytettstest.Task{
+		Statuses: []int{bytettstest.TaskRunning, bytettstest.TaskFailure},
+		Message:  "synthesis failed",
+	})
+	tts := newFakeTTS(t, srv, byteTts.WithEmotion())
+
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "长文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	_, err = tts.WaitForLongTextTask(context.Background(), created.TaskId, byteTts.WaitOptions{PollInterval: time.Millisecond})
+	var failed *byteTts.TaskFailedError
+	if !errors.As(err, &failed) || failed.TaskId != created.TaskId {
+		t.Fatalf("err = %v, want TaskFailedError", err)
+	}
+	if n := len(srv.RequestsTo(bytettstest.PathEmotionSubmit)); n != 1 {
+		t.Errorf("emotion submit requests = %d, want 1", n)
+	}
+}
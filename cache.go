@@ -0,0 +1,450 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// CacheStatusHeader 命中缓存时 TextToVoice 返回的响应带有该响应头，值为 HIT
+const CacheStatusHeader = "X-Bytetts-Cache"
+
+// Cache 短文本合成结果的缓存，键为 [CacheKey] 计算的哈希，值为 SDK 编码后的音频和附加信息
+// 实现需要支持并发调用；缓存是尽力而为的，Set 返回的错误会被忽略，不影响合成
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte) error
+	Stats() CacheStats
+}
+
+// CacheStats 缓存的统计信息
+type CacheStats struct {
+	Hits      uint64 // 命中次数
+	Misses    uint64 // 未命中次数
+	Evictions uint64 // 因容量或过期被淘汰的条目数
+	Entries   int    // 当前条目数
+	Bytes     int64  // 当前条目的总大小
+}
+
+// CacheOptions 缓存的容量和过期配置，字段为0时不限制
+type CacheOptions struct {
+	MaxEntries int           // 最大条目数
+	MaxBytes   int64         // 最大总字节数，超过后淘汰最久未使用的条目
+	TTL        time.Duration // 条目写入后的有效期
+}
+
+// WithCache 缓存短文本合成结果，相同参数的请求直接返回缓存的音频，不再请求服务端
+// 作用于 TextToVoice、TextToVoiceDisk、Synthesize 以及 TextToJoinVoiceDisk 的每个分片
+// 超长文本的内置分片策略不会跨越空行分隔的段落，修改其中一段只需要重新合成该段
+func WithCache(cache Cache) Option {
+	return func(g *GoTTS) {
+		g.cache = cache
+	}
+}
+
+// 缓存键不包含的参数，每次请求都不同或不影响合成结果
+var cacheKeyIgnored = map[string]bool{
+	"request.reqid":     true,
+	"request.operation": true,
+}
+
+// 参数的默认值，未设置和设置为默认值的请求使用相同的缓存键
+var cacheKeyDefaults = map[string]string{
+	"audio.encoding":     defaultEncoding,
+	"audio.speed_ratio":  "1",
+	"audio.volume_ratio": "1",
+	"audio.pitch_ratio":  "1",
+}
+
+// CacheKey 计算短文本合成参数的缓存键，包含 audio 和 request 的全部参数（reqid、operation 除外）以及 app.cluster
+// 数值统一格式化，零值、空字符串和默认值视为未设置，因此 1 和 1.0、未设置和 "pcm" 得到相同的键
+func CacheKey(params map[string]map[string]any) string {
+	var lines []string
+	add := func(name string, v any) {
+		s, ok := normalizeCacheValue(v)
+		if !ok || cacheKeyIgnored[name] || cacheKeyDefaults[name] == s {
+			return
+		}
+		lines = append(lines, name+"="+strconv.Quote(s))
+	}
+	for _, section := range []string{"audio", "request"} {
+		for k, v := range params[section] {
+			add(section+"."+k, v)
+		}
+	}
+	add("app.cluster", params["app"]["cluster"])
+	sort.Strings(lines)
+
+	h := sha256.New()
+	h.Write([]byte("v1\n"))
+	for _, l := range lines {
+		h.Write([]byte(l))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeCacheValue 将参数值格式化为字符串，零值返回 false
+func normalizeCacheValue(v any) (string, bool) {
+	var f float64
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, v != ""
+	case bool:
+		return "true", v
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return v.String(), true
+		}
+		f = n
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int8:
+		f = float64(v)
+	case int16:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case uint:
+		f = float64(v)
+	case uint8:
+		f = float64(v)
+	case uint16:
+		f = float64(v)
+	case uint32:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v), true
+		}
+		return string(b), true
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), f != 0
+}
+
+// cacheEntryMagic 缓存值的格式：魔数 + 4字节附加信息长度 + 附加信息 JSON + 音频
+var cacheEntryMagic = []byte("BTC1")
+
+func encodeCacheEntry(audio []byte, addition *RepAddition) ([]byte, error) {
+	meta, err := json.Marshal(addition)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, len(cacheEntryMagic)+4, len(cacheEntryMagic)+4+len(meta)+len(audio))
+	copy(b, cacheEntryMagic)
+	binary.BigEndian.PutUint32(b[len(cacheEntryMagic):], uint32(len(meta)))
+	b = append(b, meta...)
+	return append(b, audio...), nil
+}
+
+func decodeCacheEntry(b []byte) ([]byte, *RepAddition, error) {
+	head := len(cacheEntryMagic) + 4
+	if len(b) < head || !bytes.Equal(b[:len(cacheEntryMagic)], cacheEntryMagic) {
+		return nil, nil, errors.New("invalid cache entry")
+	}
+	size := binary.BigEndian.Uint32(b[len(cacheEntryMagic):head])
+	if uint64(size) > uint64(len(b)-head) {
+		return nil, nil, errors.New("invalid cache entry: truncated")
+	}
+	var addition *RepAddition
+	if err := json.Unmarshal(b[head:head+int(size)], &addition); err != nil {
+		return nil, nil, fmt.Errorf("invalid cache entry: %w", err)
+	}
+	audio := b[head+int(size):]
+	if len(audio) == 0 {
+		return nil, nil, errors.New("invalid cache entry: empty audio")
+	}
+	return audio, addition, nil
+}
+
+// cachedResponse 命中缓存时构造与服务端格式相同的响应，reqid 使用本次请求的标识
+func (g *GoTTS) cachedResponse(key string, params map[string]map[string]any) (*http.Response, bool) {
+	b, ok := g.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	audio, addition, err := decodeCacheEntry(b)
+	if err != nil {
+		return nil, false
+	}
+
+	reqid, _ := params["request"]["reqid"].(string)
+	operation, _ := params["request"]["operation"].(string)
+	body, err := json.Marshal(&Rep{
+		ReqID:     reqid,
+		Code:      codeSuccess,
+		Message:   "Success",
+		Operation: operation,
+		Sequence:  -1,
+		Data:      base64.StdEncoding.EncodeToString(audio),
+		Addition:  addition,
+	})
+	if err != nil {
+		return nil, false
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(CacheStatusHeader, "HIT")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}, true
+}
+
+// storeCache 缓存校验通过的响应，解码失败时不缓存
+func (g *GoTTS) storeCache(key string, respBody []byte) {
+	var rep Rep
+	if err := json.Unmarshal(respBody, &rep); err != nil {
+		return
+	}
+	audio, err := decodeRepAudio(&rep)
+	if err != nil {
+		return
+	}
+	entry, err := encodeCacheEntry(audio, rep.Addition)
+	if err != nil {
+		return
+	}
+	_ = g.cache.Set(key, entry)
+}
+
+// cacheCounters 命中、未命中和淘汰次数
+type cacheCounters struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func (c *cacheCounters) record(hit bool) {
+	if hit {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+func (c *cacheCounters) stats() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+	}
+}
+
+// MemoryCache 内存中的 LRU 缓存
+type MemoryCache struct {
+	counters cacheCounters // 原子操作的字段放在开头，保证32位平台上8字节对齐
+	mu       sync.Mutex
+	lru      *internal.LRU
+}
+
+// NewMemoryCache 创建内存缓存，建议设置 MaxBytes 限制内存占用
+func NewMemoryCache(opts CacheOptions) *MemoryCache {
+	c := &MemoryCache{lru: internal.NewLRU(opts.MaxEntries, opts.MaxBytes, opts.TTL)}
+	c.lru.OnEvict = func(string, []byte) {
+		atomic.AddUint64(&c.counters.evictions, 1)
+	}
+	return c
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.lru.Get(key)
+	c.counters.record(ok)
+	return value, ok
+}
+
+func (c *MemoryCache) Set(key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Add(key, value, int64(len(value)), c.lru.Now())
+	return nil
+}
+
+func (c *MemoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.counters.stats()
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.lru.Bytes()
+	return stats
+}
+
+// FileCache 文件系统缓存，每个条目一个文件，位于 dir/键的前两位/键
+// 创建时扫描目录重建索引，按文件修改时间计算过期和淘汰顺序
+// 同一个目录同时只应由一个 FileCache 使用
+type FileCache struct {
+	counters cacheCounters // 原子操作的字段放在开头，保证32位平台上8字节对齐
+	dir      string
+	mu       sync.Mutex
+	lru      *internal.LRU
+}
+
+// NewFileCache 创建文件系统缓存，目录不存在时自动创建，已过期和超出容量的文件会被删除
+func NewFileCache(dir string, opts CacheOptions) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir error: %w", err)
+	}
+	c := &FileCache{dir: dir, lru: internal.NewLRU(opts.MaxEntries, opts.MaxBytes, opts.TTL)}
+
+	type file struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !validCacheKey(info.Name()) {
+			return nil
+		}
+		if path != c.path(info.Name()) {
+			return nil
+		}
+		files = append(files, file{key: info.Name(), size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan cache dir error: %w", err)
+	}
+
+	// 按修改时间从旧到新加入索引，超出容量时淘汰最旧的文件，重建索引时的淘汰不计入统计
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	c.lru.OnEvict = func(key string, _ []byte) {
+		_ = os.Remove(c.path(key))
+	}
+	now := c.lru.Now()
+	for _, f := range files {
+		if opts.TTL > 0 && now.Sub(f.modTime) >= opts.TTL {
+			_ = os.Remove(c.path(f.key))
+			continue
+		}
+		if !c.lru.Add(f.key, nil, f.size, f.modTime) {
+			_ = os.Remove(c.path(f.key))
+		}
+	}
+	c.lru.OnEvict = func(key string, _ []byte) {
+		_ = os.Remove(c.path(key))
+		atomic.AddUint64(&c.counters.evictions, 1)
+	}
+	return c, nil
+}
+
+// validCacheKey 只接受十六进制的键，避免路径穿越
+func validCacheKey(key string) bool {
+	if len(key) < 2 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		ch := key[i]
+		if (ch < '0' || ch > '9') && (ch < 'a' || ch > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	if !validCacheKey(key) {
+		c.counters.record(false)
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.lru.Get(key); !ok {
+		c.counters.record(false)
+		return nil, false
+	}
+	value, err := os.ReadFile(c.path(key))
+	if err != nil {
+		// 文件被外部删除
+		c.lru.Remove(key)
+		c.counters.record(false)
+		return nil, false
+	}
+	c.counters.record(true)
+	return value, true
+}
+
+// Set 先写入临时文件再重命名，进程中断时不会留下不完整的条目
+func (c *FileCache) Set(key string, value []byte) error {
+	if !validCacheKey(key) {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(value)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if !c.lru.Add(key, nil, int64(len(value)), c.lru.Now()) {
+		_ = os.Remove(path)
+	}
+	return nil
+}
+
+func (c *FileCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.counters.stats()
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.lru.Bytes()
+	return stats
+}
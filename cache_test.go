@@ -0,0 +1,270 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := func() map[string]map[string]any {
+		return map[string]map[string]any{
+			"app":     {"appid": "appid", "cluster": "cluster"},
+			"user":    {"uid": "uid"},
+			"audio":   {"voice_type": "BV406_V2_streaming", "encoding": "mp3", "speed_ratio": 1.0},
+			"request": {"reqid": "r1", "text": "你好", "operation": "query"},
+		}
+	}
+	key := CacheKey(base())
+	if len(key) != 64 {
+		t.Fatalf("key = %q", key)
+	}
+
+	same := []func(p map[string]map[string]any){
+		func(p map[string]map[string]any) { p["request"]["reqid"] = "r2" },
+		func(p map[string]map[string]any) { p["request"]["operation"] = "submit" },
+		func(p map[string]map[string]any) { p["user"]["uid"] = "other" },
+		func(p map[string]map[string]any) { p["app"]["appid"] = "other" },
+		func(p map[string]map[string]any) { p["audio"]["speed_ratio"] = 1 },
+		func(p map[string]map[string]any) { delete(p["audio"], "speed_ratio") },
+		func(p map[string]map[string]any) { p["audio"]["pitch_ratio"] = 1.0 },
+		func(p map[string]map[string]any) { p["audio"]["rate"] = 0 },
+		func(p map[string]map[string]any) { p["audio"]["emotion"] = "" },
+	}
+	for i, modify := range same {
+		p := base()
+		modify(p)
+		if got := CacheKey(p); got != key {
+			t.Errorf("case %d: key changed", i)
+		}
+	}
+
+	different := []func(p map[string]map[string]any){
+		func(p map[string]map[string]any) { p["request"]["text"] = "你好。" },
+		func(p map[string]map[string]any) { p["audio"]["voice_type"] = "BV700_streaming" },
+		func(p map[string]map[string]any) { p["audio"]["encoding"] = "wav" },
+		func(p map[string]map[string]any) { p["audio"]["speed_ratio"] = 1.2 },
+		func(p map[string]map[string]any) { p["audio"]["rate"] = 16000 },
+		func(p map[string]map[string]any) { p["audio"]["emotion"] = "happy" },
+		func(p map[string]map[string]any) { p["request"]["silence_duration"] = 50 },
+		func(p map[string]map[string]any) { p["app"]["cluster"] = "other" },
+	}
+	for i, modify := range different {
+		p := base()
+		modify(p)
+		if got := CacheKey(p); got == key {
+			t.Errorf("case %d: key should change", i)
+		}
+	}
+
+	// 未设置编码与默认编码 pcm 相同
+	p1, p2 := base(), base()
+	delete(p1["audio"], "encoding")
+	p2["audio"]["encoding"] = defaultEncoding
+	if CacheKey(p1) != CacheKey(p2) {
+		t.Error("default encoding should not change the key")
+	}
+}
+
+func TestCacheEntry(t *testing.T) {
+	addition := &RepAddition{Duration: "1200", Frontend: `{"words":[]}`}
+	b, err := encodeCacheEntry([]byte("audio"), addition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio, got, err := decodeCacheEntry(b)
+	if err != nil || string(audio) != "audio" || *got != *addition {
+		t.Fatalf("decode = %q, %+v, %v", audio, got, err)
+	}
+	for _, bad := range [][]byte{nil, []byte("BTC1"), b[:10], append([]byte("XXXX"), b[4:]...)} {
+		if _, _, err := decodeCacheEntry(bad); err == nil {
+			t.Errorf("decode %q should fail", bad)
+		}
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(CacheOptions{MaxEntries: 2, TTL: time.Minute})
+	now := time.Now()
+	c.lru.Now = func() time.Time { return now }
+
+	_ = c.Set("a", []byte("aa"))
+	_ = c.Set("b", []byte("bb"))
+	if v, ok := c.Get("a"); !ok || string(v) != "aa" {
+		t.Errorf("Get(a) = %q, %v", v, ok)
+	}
+	_ = c.Set("c", []byte("cc"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should be evicted")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("a should expire")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 2 || stats.Entries != 1 || stats.Bytes != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	key1, key2, key3 := strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("c", 64)
+
+	c, err := NewFileCache(dir, CacheOptions{MaxBytes: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(key1, []byte("11111")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(key2, []byte("22222")); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.Get(key1); !ok || string(v) != "11111" {
+		t.Errorf("Get = %q, %v", v, ok)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "aa", key1)); err != nil {
+		t.Errorf("cache file err = %v", err)
+	}
+
+	// key2 最久未使用，被淘汰并删除文件
+	if err := c.Set(key3, []byte("333")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get(key2); ok {
+		t.Error("key2 should be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bb", key2)); !os.IsNotExist(err) {
+		t.Errorf("evicted file should be removed, err = %v", err)
+	}
+	if stats := c.Stats(); stats.Entries != 2 || stats.Bytes != 8 || stats.Evictions != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if err := c.Set("../escape", []byte("x")); err == nil {
+		t.Error("invalid key should be rejected")
+	}
+	if _, ok := c.Get("../escape"); ok {
+		t.Error("invalid key should miss")
+	}
+
+	// 重新打开时从目录重建索引
+	reopened, err := NewFileCache(dir, CacheOptions{MaxBytes: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := reopened.Get(key3); !ok || string(v) != "333" {
+		t.Errorf("reopened Get = %q, %v", v, ok)
+	}
+	if stats := reopened.Stats(); stats.Entries != 2 {
+		t.Errorf("reopened stats = %+v", stats)
+	}
+
+	// 重新打开时删除过期的文件
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "aa", key1), old, old); err != nil {
+		t.Fatal(err)
+	}
+	expiring, err := NewFileCache(dir, CacheOptions{TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := expiring.Get(key1); ok {
+		t.Error("expired file should not be loaded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "aa", key1)); !os.IsNotExist(err) {
+		t.Errorf("expired file should be removed, err = %v", err)
+	}
+}
+
+func TestWithCacheSynthesize(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(bytettstest.Response{Audio: []byte("audio"), Duration: "800"})
+	cache := NewMemoryCache(CacheOptions{})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithCache(cache))
+
+	first, err := tts.Synthesize(context.Background(), newTestRequest())
+	if err != nil {
+		t.Fatalf("Synthesize err = %v", err)
+	}
+	req := newTestRequest()
+	req.Audio.SpeedRatio = 0 // 与默认值 1 使用相同的缓存
+	second, err := tts.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Synthesize err = %v", err)
+	}
+
+	if first.Cached || !second.Cached {
+		t.Errorf("Cached = %v, %v", first.Cached, second.Cached)
+	}
+	if string(second.Audio) != "audio" || second.Duration != first.Duration || second.ReqID == first.ReqID {
+		t.Errorf("cached result = %+v", second)
+	}
+	if n := len(srv.RequestsTo(bytettstest.PathTTS)); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// 失败的请求不缓存
+	srv.EnqueueTTS(bytettstest.Response{Code: 3011, Message: "invalid text"})
+	req.Request.Text = "新的文本"
+	if _, err := tts.Synthesize(context.Background(), req); err == nil {
+		t.Fatal("expected error")
+	}
+	if stats := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("failed request should not be cached, stats = %+v", stats)
+	}
+}
+
+func TestWithCacheJoinParagraphs(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithCache(NewMemoryCache(CacheOptions{})))
+
+	paragraphs := []string{
+		strings.Repeat("第一段。", 40) + "\n\n",
+		strings.Repeat("第二段。", 40) + "\n\n",
+		strings.Repeat("第三段。", 40),
+	}
+	join := func(text string) string {
+		req := newTestRequest()
+		req.Audio.Encoding = "pcm"
+		req.Request.Text = text
+		var buf bytes.Buffer
+		if err := tts.TextToJoinVoiceRequestWriter(context.Background(), req, &buf); err != nil {
+			t.Fatalf("TextToJoinVoiceRequestWriter err = %v", err)
+		}
+		return buf.String()
+	}
+
+	text := strings.Join(paragraphs, "")
+	if got := join(text); got != text {
+		t.Errorf("audio = %q", got)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Fatalf("requests = %d, want one per paragraph", n)
+	}
+
+	// 只修改第二段，其余段落命中缓存
+	paragraphs[1] = strings.Repeat("修改后的第二段。", 20) + "\n\n"
+	text = strings.Join(paragraphs, "")
+	if got := join(text); got != text {
+		t.Errorf("audio = %q", got)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 4 || reqs[3].Text() != paragraphs[1] {
+		t.Errorf("requests = %d, want only the edited paragraph", len(reqs))
+	}
+}
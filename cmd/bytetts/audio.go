@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+)
+
+const defaultVoice = "BV406_V2_streaming"
+
+// audioFlags 与 audio.* 和 request.* 一一对应的参数
+type audioFlags struct {
+	uid             string
+	voice           string
+	emotion         string
+	encoding        string
+	compressionRate int
+	rate            int
+	speed           float64
+	volume          float64
+	pitch           float64
+	language        string
+	textType        string
+	silenceDuration int
+}
+
+func (a *audioFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.uid, "uid", "", "用户标识，方便问题定位")
+	fs.StringVar(&a.voice, "voice", defaultVoice, "音色代号 audio.voice_type，参见 bytetts voices")
+	fs.StringVar(&a.emotion, "emotion", "", "音色情感 audio.emotion")
+	fs.StringVar(&a.encoding, "encoding", "mp3", "音频编码 audio.encoding：wav / pcm / ogg_opus / mp3")
+	fs.IntVar(&a.compressionRate, "compression-rate", 0, "opus 编码压缩比 audio.compression_rate")
+	fs.IntVar(&a.rate, "rate", 0, "采样率 audio.rate：8000 / 16000 / 24000，为0时使用服务端默认值")
+	fs.Float64Var(&a.speed, "speed", 1, "语速 audio.speed_ratio：[0.2,3]")
+	fs.Float64Var(&a.volume, "volume", 1, "音量 audio.volume_ratio：[0.1,3]")
+	fs.Float64Var(&a.pitch, "pitch", 1, "音高 audio.pitch_ratio：[0.1,3]")
+	fs.StringVar(&a.language, "language", "", "语言类型 audio.language")
+	fs.StringVar(&a.textType, "text-type", "", "文本类型 request.text_type：plain / ssml")
+	fs.IntVar(&a.silenceDuration, "silence-duration", 0, "句尾静音时长 request.silence_duration，单位为ms")
+}
+
+// request 生成结构化的请求参数
+func (a *audioFlags) request(text string) *byteTts.SynthesisRequest {
+	return &byteTts.SynthesisRequest{
+		User: byteTts.UserConfig{Uid: a.uid},
+		Audio: byteTts.AudioConfig{
+			VoiceType:       a.voice,
+			Emotion:         a.emotion,
+			Encoding:        a.encoding,
+			CompressionRate: a.compressionRate,
+			Rate:            a.rate,
+			SpeedRatio:      a.speed,
+			VolumeRatio:     a.volume,
+			PitchRatio:      a.pitch,
+			Language:        a.language,
+		},
+		Request: byteTts.RequestConfig{
+			Text:            text,
+			TextType:        a.textType,
+			SilenceDuration: a.silenceDuration,
+		},
+	}
+}
+
+// longParams 生成长文本任务的参数，字段名与 tts_async 接口一致
+func (a *audioFlags) longParams(text string) map[string]any {
+	params := map[string]any{
+		"text":       text,
+		"voice_type": a.voice,
+		"format":     a.encoding,
+		"speed":      a.speed,
+		"volume":     a.volume,
+		"pitch":      a.pitch,
+	}
+	if a.rate > 0 {
+		params["sample_rate"] = a.rate
+	}
+	if a.emotion != "" {
+		params["style"] = a.emotion
+	}
+	if a.language != "" {
+		params["language"] = a.language
+	}
+	return params
+}
+
+// textFlags 待合成的文本，可以来自 -text、-file 或位置参数
+type textFlags struct {
+	text string
+	file string
+}
+
+func (t *textFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&t.text, "text", "", "待合成的文本")
+	fs.StringVar(&t.file, "file", "", "从文件读取待合成的文本，- 表示标准输入")
+}
+
+// read 读取文本，未指定 -text 和 -file 时使用位置参数
+func (t *textFlags) read(e *env, args []string) (string, error) {
+	var text string
+	switch {
+	case t.text != "" && t.file != "":
+		return "", usageErrorf("-text and -file are mutually exclusive")
+	case t.text != "":
+		text = t.text
+	case t.file == "-":
+		b, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return "", err
+		}
+		text = string(b)
+	case t.file != "":
+		b, err := os.ReadFile(t.file)
+		if err != nil {
+			return "", err
+		}
+		text = string(b)
+	default:
+		text = strings.Join(args, " ")
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", usageErrorf("text cannot be empty, use -text, -file or positional arguments")
+	}
+	return text, nil
+}
+
+// fileExt 音频编码对应的文件扩展名
+func fileExt(encoding string) string {
+	switch encoding {
+	case "ogg_opus":
+		return ".ogg"
+	case "":
+		return ".pcm"
+	}
+	return "." + encoding
+}
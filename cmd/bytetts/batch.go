@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// batchItem 批量合成的一行输入，未设置的音频参数使用命令行参数
+type batchItem struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Output    string `json:"output"`
+	VoiceType string `json:"voice_type"`
+	Emotion   string `json:"emotion"`
+	Encoding  string `json:"encoding"`
+}
+
+// batchResult 批量合成的一行输出，顺序与输入一致
+type batchResult struct {
+	Line   int          `json:"line"`
+	ID     string       `json:"id,omitempty"`
+	Output string       `json:"output,omitempty"`
+	Bytes  int64        `json:"bytes,omitempty"`
+	Error  *errorOutput `json:"error,omitempty"`
+}
+
+func runBatch(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "batch")
+	var cf clientFlags
+	var af audioFlags
+	cf.register(fs)
+	af.register(fs)
+	input := fs.String("input", "-", "JSON Lines 输入文件，每行包含 id / text / output / voice_type / emotion / encoding，- 表示标准输入")
+	outDir := fs.String("out-dir", ".", "未设置 output 时音频文件的目录，文件名为 id 或行号加上扩展名")
+	concurrency := fs.Int("concurrency", 2, "同时合成的条数")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *concurrency <= 0 {
+		return usageErrorf("-concurrency must be positive")
+	}
+
+	var r io.Reader = e.stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	items, err := readBatch(r)
+	if err != nil {
+		return err
+	}
+
+	tts, ctx, cancel, err := cf.client(ctx, e)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	results := make([]batchResult, len(items))
+	sem := make(chan struct{}, *concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		i, item := i, item
+		results[i] = batchResult{Line: item.line, ID: item.ID}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			res := &results[i]
+
+			a := af
+			override(&a.voice, item.VoiceType)
+			override(&a.emotion, item.Emotion)
+			override(&a.encoding, item.Encoding)
+			req := a.request(item.Text)
+			if err := req.Validate(); err != nil {
+				res.Error = &errorOutput{Error: err.Error()}
+				return
+			}
+			res.Output = item.Output
+			if res.Output == "" {
+				name := item.ID
+				if name == "" {
+					name = strconv.Itoa(item.line)
+				}
+				res.Output = filepath.Join(*outDir, name+cf.fileExt(a.encoding))
+			}
+			n, err := synthesizeFile(ctx, tts, req, res.Output)
+			if err != nil {
+				out := newErrorOutput(err)
+				res.Error = &out
+				return
+			}
+			res.Bytes = n
+		}()
+	}
+	wg.Wait()
+
+	var failed int
+	for _, res := range results {
+		if res.Error != nil {
+			failed++
+		}
+		if err := printJSON(e.stdout, res); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d items failed", failed, len(results))
+	}
+	return nil
+}
+
+// lineItem 带行号的输入
+type lineItem struct {
+	batchItem
+	line int
+}
+
+// readBatch 读取 JSON Lines，跳过空行
+func readBatch(r io.Reader) ([]lineItem, error) {
+	var items []lineItem
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	line := 0
+	for sc.Scan() {
+		line++
+		b := sc.Bytes()
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+		var item batchItem
+		if err := json.Unmarshal(b, &item); err != nil {
+			return nil, usageErrorf("line %d: %v", line, err)
+		}
+		items = append(items, lineItem{batchItem: item, line: line})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, usageErrorf("no items in input")
+	}
+	return items, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+)
+
+// 凭证相关的环境变量，与仓库的测试保持一致
+const (
+	envAppId   = "byte_appId"
+	envToken   = "byte_token"
+	envCluster = "byte_cluster"
+	envBaseURL = "byte_baseURL"
+	// 配置文件路径
+	envConfig = "BYTETTS_CONFIG"
+)
+
+// 未指定配置文件时，存在 ~/.bytetts.json 则读取
+const defaultConfigName = ".bytetts.json"
+
+// config 配置文件的内容
+type config struct {
+	AppId   string `json:"appid"`
+	Token   string `json:"token"`
+	Cluster string `json:"cluster"`
+	BaseURL string `json:"base_url"`
+}
+
+// clientFlags 创建 GoTTS 的公共参数
+type clientFlags struct {
+	configPath string
+	cfg        config
+	emotion    bool
+	cacheDir   string
+	strict     bool
+	pcmWav     bool
+	smoothing  byteTts.JoinSmoothing
+	trim       bool
+	timeout    time.Duration
+	retries    int
+}
+
+func (c *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.configPath, "config", "", "JSON 配置文件，包含 appid / token / cluster / base_url，默认读取 $"+envConfig+" 或 ~/"+defaultConfigName)
+	fs.StringVar(&c.cfg.AppId, "appid", "", "应用标识，默认读取 $"+envAppId)
+	fs.StringVar(&c.cfg.Token, "token", "", "应用令牌，默认读取 $"+envToken)
+	fs.StringVar(&c.cfg.Cluster, "cluster", "", "业务集群，默认读取 $"+envCluster)
+	fs.StringVar(&c.cfg.BaseURL, "base-url", "", "服务地址，默认读取 $"+envBaseURL+"，未设置时为 "+byteTts.DefaultBaseURL)
+	fs.StringVar(&c.cacheDir, "cache-dir", "", "缓存短文本合成结果的目录，相同参数的文本不再请求服务端")
+	fs.BoolVar(&c.strict, "strict-voices", false, "拒绝内置音色目录中没有的音色，参见 voices 命令")
+	fs.BoolVar(&c.pcmWav, "pcm-wav", false, "编码为 pcm 时输出带 WAV 头部的文件，默认扩展名为 .wav")
+	fs.BoolVar(&c.trim, "trim-silence", false, "pcm / wav 分片拼接时裁剪分片边界的静音，设置 -chunk-pause 或 -chunk-crossfade 时自动开启")
+	fs.DurationVar(&c.smoothing.Pause, "chunk-pause", 0, "裁剪静音后分片之间插入的停顿，例如 200ms")
+	fs.DurationVar(&c.smoothing.Crossfade, "chunk-crossfade", 0, "裁剪静音后分片之间交叉淡化的时长，例如 20ms，设置后忽略 -chunk-pause")
+	fs.DurationVar(&c.timeout, "timeout", 0, "命令的整体超时时间，为0时不限制")
+	fs.IntVar(&c.retries, "retries", byteTts.DefaultRetryPolicy.MaxAttempts, "临时错误的最大尝试次数（包含首次请求），小于等于1时不重试")
+}
+
+// fileExt 输出文件的扩展名，开启 -pcm-wav 时 pcm 使用 .wav
+func (c *clientFlags) fileExt(encoding string) string {
+	if c.pcmWav && (encoding == "" || encoding == "pcm") {
+		return ".wav"
+	}
+	return fileExt(encoding)
+}
+
+// registerEmotion 长文本命令使用情感预测版接口的参数
+func (c *clientFlags) registerEmotion(fs *flag.FlagSet) {
+	fs.BoolVar(&c.emotion, "emotion-predict", false, "使用情感预测版的长文本接口")
+}
+
+// load 合并配置文件、环境变量和命令行参数，后者覆盖前者
+func (c *clientFlags) load(e *env) (config, error) {
+	path := c.configPath
+	explicit := path != ""
+	if path == "" {
+		path = e.getenv(envConfig)
+		explicit = path != ""
+	}
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, defaultConfigName)
+		}
+	}
+
+	var cfg config
+	if path != "" {
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, &cfg); err != nil {
+				return cfg, fmt.Errorf("parse config %s error: %w", path, err)
+			}
+		case explicit || !errors.Is(err, os.ErrNotExist):
+			return cfg, fmt.Errorf("read config error: %w", err)
+		}
+	}
+
+	override(&cfg.AppId, e.getenv(envAppId), c.cfg.AppId)
+	override(&cfg.Token, e.getenv(envToken), c.cfg.Token)
+	override(&cfg.Cluster, e.getenv(envCluster), c.cfg.Cluster)
+	override(&cfg.BaseURL, e.getenv(envBaseURL), c.cfg.BaseURL)
+	return cfg, nil
+}
+
+// override 依次使用非空的值覆盖 dst
+func override(dst *string, values ...string) {
+	for _, v := range values {
+		if v != "" {
+			*dst = v
+		}
+	}
+}
+
+// client 根据参数创建 GoTTS，并返回受 -timeout 控制的 ctx
+func (c *clientFlags) client(ctx context.Context, e *env) (byteTts.GoTTSInter, context.Context, context.CancelFunc, error) {
+	cfg, err := c.load(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cfg.AppId == "" || cfg.Token == "" || cfg.Cluster == "" {
+		return nil, nil, nil, usageErrorf("missing credentials: set -appid / -token / -cluster, $%s / $%s / $%s or a config file", envAppId, envToken, envCluster)
+	}
+
+	opts := []byteTts.Option{
+		byteTts.WithAppId(cfg.AppId),
+		byteTts.WithToken(cfg.Token),
+		byteTts.WithCluster(cfg.Cluster),
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, byteTts.WithBaseURL(cfg.BaseURL))
+	}
+	if c.emotion {
+		opts = append(opts, byteTts.WithEmotion())
+	}
+	if c.cacheDir != "" {
+		cache, err := byteTts.NewFileCache(c.cacheDir, byteTts.CacheOptions{})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		opts = append(opts, byteTts.WithCache(cache))
+	}
+	if c.strict {
+		opts = append(opts, byteTts.WithStrictVoices())
+	}
+	if c.pcmWav {
+		opts = append(opts, byteTts.WithPCMToWav())
+	}
+	if c.trim || c.smoothing.Pause > 0 || c.smoothing.Crossfade > 0 {
+		opts = append(opts, byteTts.WithJoinSmoothing(c.smoothing))
+	}
+	if c.retries > 1 {
+		policy := byteTts.DefaultRetryPolicy
+		policy.MaxAttempts = c.retries
+		opts = append(opts, byteTts.WithRetryPolicy(policy))
+	}
+
+	var cancel context.CancelFunc
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	tts, err := byteTts.NewGoTTS(ctx, opts...)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	return tts, ctx, cancel, nil
+}
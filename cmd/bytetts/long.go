@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+)
+
+// longOutput 长文本任务命令的输出，下载音频后带上文件路径和大小
+type longOutput struct {
+	*byteTts.TtsAsyncQueryRep
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Output    string `json:"output,omitempty"`
+	Bytes     int64  `json:"bytes,omitempty"`
+}
+
+func newLongOutput(rep *byteTts.TtsAsyncQueryRep) longOutput {
+	out := longOutput{TtsAsyncQueryRep: rep, Status: rep.TaskStatus.String()}
+	if t := rep.ExpiresAt(); !t.IsZero() {
+		out.ExpiresAt = t.Format(time.RFC3339)
+	}
+	return out
+}
+
+var longCommands = map[string]func(ctx context.Context, e *env, args []string) error{
+	"submit":   runLongSubmit,
+	"status":   runLongStatus,
+	"wait":     runLongWait,
+	"download": runLongDownload,
+}
+
+func runLong(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("missing subcommand: submit / status / wait / download")
+	}
+	sub, ok := longCommands[args[0]]
+	if !ok {
+		return usageErrorf("unknown subcommand %q, want submit / status / wait / download", args[0])
+	}
+	return sub(ctx, e, args[1:])
+}
+
+func runLongSubmit(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "long submit")
+	var cf clientFlags
+	var af audioFlags
+	var tf textFlags
+	cf.register(fs)
+	cf.registerEmotion(fs)
+	af.register(fs)
+	tf.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	text, err := tf.read(e, fs.Args())
+	if err != nil {
+		return err
+	}
+	tts, ctx, cancel, err := cf.client(ctx, e)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	rep, err := tts.LongTextToVoiceCreateContext(ctx, af.longParams(text))
+	if err != nil {
+		return err
+	}
+	return printJSON(e.stdout, rep)
+}
+
+// parseTaskFlags 解析查询类命令的参数，任务ID可以通过 -task 或第一个位置参数传入
+func parseTaskFlags(fs *flag.FlagSet, cf *clientFlags, args []string) (string, error) {
+	cf.register(fs)
+	cf.registerEmotion(fs)
+	task := fs.String("task", "", "任务ID，也可以作为第一个位置参数")
+	if err := parseFlags(fs, args); err != nil {
+		return "", err
+	}
+	id := *task
+	if id == "" && fs.NArg() > 0 {
+		id = fs.Arg(0)
+	}
+	if id == "" {
+		return "", usageErrorf("missing task id")
+	}
+	return id, nil
+}
+
+func runLongStatus(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "long status")
+	var cf clientFlags
+	id, err := parseTaskFlags(fs, &cf, args)
+	if err != nil {
+		return err
+	}
+	tts, ctx, cancel, err := cf.client(ctx, e)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	rep, err := tts.LongTextToVoiceIdContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printJSON(e.stdout, newLongOutput(rep))
+}
+
+func runLongWait(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "long wait")
+	var cf clientFlags
+	interval := fs.Duration("interval", time.Second, "首次查询前的等待时间，之后逐渐增加")
+	maxInterval := fs.Duration("max-interval", time.Second*10, "查询间隔上限")
+	progress := fs.Bool("progress", false, "每次查询后将任务状态以 JSON 输出到标准错误")
+	out := fs.String("out", "", "合成成功后下载音频到该文件")
+	id, err := parseTaskFlags(fs, &cf, args)
+	if err != nil {
+		return err
+	}
+	tts, ctx, cancel, err := cf.client(ctx, e)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	opts := byteTts.WaitOptions{PollInterval: *interval, MaxInterval: *maxInterval}
+	if *progress {
+		opts.OnProgress = func(rep *byteTts.TtsAsyncQueryRep) {
+			_ = printJSON(e.stderr, newLongOutput(rep))
+		}
+	}
+	rep, err := tts.WaitForLongTextTask(ctx, id, opts)
+	if err != nil {
+		return err
+	}
+	result := newLongOutput(rep)
+	if *out != "" {
+		if result.Bytes, err = downloadFile(ctx, tts, rep, *out); err != nil {
+			return err
+		}
+		result.Output = *out
+	}
+	return printJSON(e.stdout, result)
+}
+
+func runLongDownload(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "long download")
+	var cf clientFlags
+	out := fs.String("out", "", "输出的音频文件，默认为任务ID加上 .mp3")
+	id, err := parseTaskFlags(fs, &cf, args)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = id + ".mp3"
+	}
+	tts, ctx, cancel, err := cf.client(ctx, e)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	rep, err := tts.LongTextToVoiceIdContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rep.TaskStatus != byteTts.TaskStatusSuccess {
+		return fmt.Errorf("task %s is %s, audio is not ready", id, rep.TaskStatus)
+	}
+	result := newLongOutput(rep)
+	if result.Bytes, err = downloadFile(ctx, tts, rep, *out); err != nil {
+		return err
+	}
+	result.Output = *out
+	return printJSON(e.stdout, result)
+}
+
+// downloadFile 下载长文本音频到文件，失败时删除不完整的文件
+func downloadFile(ctx context.Context, tts byteTts.GoTTSInter, rep *byteTts.TtsAsyncQueryRep, path string) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	err = tts.DownloadLongTextAudio(ctx, rep, f)
+	var n int64
+	if err == nil {
+		n, err = f.Seek(0, io.SeekCurrent)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,176 @@
+// Command bytetts 字节语音合成命令行工具，无需编写代码即可合成短文本、提交长文本任务和批量合成
+//
+//	bytetts say -text "你好" -voice BV406_V2_streaming -out hello.mp3
+//	bytetts long submit -file article.txt
+//	bytetts long wait -out article.mp3 <task_id>
+//	bytetts voices
+//	bytetts batch -input prompts.jsonl -out-dir ./audio
+//
+// 凭证依次从配置文件、环境变量（byte_appId / byte_token / byte_cluster）和命令行参数读取，后者覆盖前者
+// 命令的结果以 JSON 输出到标准输出，错误以 JSON 输出到标准错误并返回非0退出码
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// env 命令的运行环境，测试时替换为内存中的输入输出
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// command 子命令
+type command struct {
+	usage string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"say":    {usage: "合成文本并写入音频文件，超长文本自动分片", run: runSay},
+	"long":   {usage: "长文本任务：submit / status / wait / download", run: runLong},
+	"voices": {usage: "列出可用的音色", run: runVoices},
+	"batch":  {usage: "按 JSON Lines 文件批量合成", run: runBatch},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, &env{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+	}, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run 执行子命令并返回退出码
+func run(ctx context.Context, e *env, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		printUsage(e.stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "bytetts: unknown command %q\n\n", args[0])
+		printUsage(e.stderr)
+		return exitUsage
+	}
+
+	err := cmd.run(ctx, e, args[1:])
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, new(*usageError)):
+		fmt.Fprintf(e.stderr, "bytetts %s: %v\n", args[0], err)
+		return exitUsage
+	}
+	printError(e.stderr, err)
+	return exitError
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: bytetts <command> [flags]")
+	fmt.Fprintln(w)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "bytetts <command> -h" for the flags of each command`)
+}
+
+// usageError 参数错误，退出码为2
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usageErrorf(format string, a ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+// newFlagSet 创建子命令的参数集合，解析失败时返回错误而不是退出进程
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bytetts "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parseFlags 解析参数，参数错误统一转为 usageError
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{msg: err.Error()}
+	}
+	return nil
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// errorOutput 错误的 JSON 输出，接口错误带上返回码和请求标识
+type errorOutput struct {
+	Error      string `json:"error"`
+	Kind       string `json:"kind,omitempty"`
+	Code       int    `json:"code,omitempty"`
+	ReqID      string `json:"reqid,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	TaskId     string `json:"task_id,omitempty"`
+}
+
+func newErrorOutput(err error) errorOutput {
+	out := errorOutput{Error: err.Error()}
+	var apiErr *byteTts.APIError
+	if errors.As(err, &apiErr) {
+		out.Kind = apiErr.Kind.String()
+		out.Code = apiErr.Code
+		out.ReqID = apiErr.ReqID
+		out.HTTPStatus = apiErr.HTTPStatus
+	}
+	var failed *byteTts.TaskFailedError
+	if errors.As(err, &failed) {
+		out.TaskId = failed.TaskId
+		out.Code = failed.Result.Code
+	}
+	return out
+}
+
+func printError(w io.Writer, err error) {
+	_ = printJSON(w, newErrorOutput(err))
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+// runCLI 使用模拟服务运行命令，凭证通过环境变量传入
+func runCLI(t *testing.T, srv *bytettstest.Server, stdin string, args ...string) (int, string, string) {
+	t.Setenv("HOME", t.TempDir())
+	vars := map[string]string{}
+	if srv != nil {
+		vars = map[string]string{
+			envAppId:   "appid",
+			envToken:   "token",
+			envCluster: "cluster",
+			envBaseURL: srv.URL,
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), &env{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(k string) string { return vars[k] },
+	}, args)
+	return code, stdout.String(), stderr.String()
+}
+
+func decodeJSON(t *testing.T, s string, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", s, err)
+	}
+}
+
+func TestSay(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.SetToken("token")
+	out := filepath.Join(t.TempDir(), "hello.pcm")
+
+	code, stdout, stderr := runCLI(t, srv, "", "say", "-voice", "BV700_streaming", "-encoding", "pcm", "-rate", "16000", "-speed", "1.2", "-out", out, "你好")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var res sayOutput
+	decodeJSON(t, stdout, &res)
+	if res.Output != out || res.Bytes != int64(len("你好")) || res.VoiceType != "BV700_streaming" {
+		t.Errorf("output = %+v", res)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "你好" {
+		t.Errorf("audio = %q", b)
+	}
+
+	var body map[string]map[string]any
+	if err := srv.RequestsTo(bytettstest.PathTTS)[0].JSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body["audio"]["rate"] != float64(16000) || body["audio"]["speed_ratio"] != 1.2 || body["audio"]["encoding"] != "pcm" {
+		t.Errorf("audio params = %v", body["audio"])
+	}
+}
+
+func TestSaySubtitle(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+	out, srt := filepath.Join(dir, "hello.pcm"), filepath.Join(dir, "hello.srt")
+
+	code, stdout, stderr := runCLI(t, srv, "", "say", "-encoding", "pcm", "-out", out, "-subtitle", srt, "你好。世界")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var res sayOutput
+	decodeJSON(t, stdout, &res)
+	if res.Subtitle != srt || res.Cues != 2 {
+		t.Errorf("output = %+v", res)
+	}
+	// 模拟服务每个字 100 毫秒
+	want := "1\n00:00:00,000 --> 00:00:00,300\n你好。\n\n2\n00:00:00,300 --> 00:00:00,500\n世界\n\n"
+	if b, _ := os.ReadFile(srt); string(b) != want {
+		t.Errorf("srt = %q", b)
+	}
+
+	if code, _, _ := runCLI(t, srv, "", "say", "-subtitle", "out.ass", "你好"); code != exitUsage {
+		t.Errorf("unsupported subtitle exit code = %d", code)
+	}
+}
+
+func TestSayCache(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		code, _, stderr := runCLI(t, srv, "", "say", "-cache-dir", filepath.Join(dir, "cache"), "-out", filepath.Join(dir, "out.mp3"), "你好")
+		if code != exitOK {
+			t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+		}
+	}
+	if n := len(srv.RequestsTo(bytettstest.PathTTS)); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestSayFromStdin(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	out := filepath.Join(t.TempDir(), "out.mp3")
+
+	code, _, stderr := runCLI(t, srv, "标准输入的文本", "say", "-file", "-", "-out", out)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	if reqs := srv.RequestsTo(bytettstest.PathTTS); len(reqs) != 1 || reqs[0].Text() != "标准输入的文本" {
+		t.Errorf("requests = %+v", reqs)
+	}
+}
+
+func TestSayAPIError(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(bytettstest.Response{Code: 3050, Message: "voice not found"})
+	out := filepath.Join(t.TempDir(), "out.mp3")
+
+	code, stdout, stderr := runCLI(t, srv, "", "say", "-retries", "1", "-out", out, "你好")
+	if code != exitError || stdout != "" {
+		t.Fatalf("exit code = %d, stdout = %s", code, stdout)
+	}
+	var res errorOutput
+	decodeJSON(t, stderr, &res)
+	if res.Code != 3050 || res.Kind != "invalid_param" || res.ReqID == "" {
+		t.Errorf("error output = %+v", res)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("incomplete file should be removed, stat err = %v", err)
+	}
+}
+
+func TestSayStrictVoices(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	out := filepath.Join(t.TempDir(), "out.mp3")
+
+	code, _, stderr := runCLI(t, srv, "", "say", "-strict-voices", "-voice", "BV999_streaming", "-out", out, "你好")
+	if code != exitError || !strings.Contains(stderr, "unknown voice_type") {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("requests = %d, unknown voice should not be sent", n)
+	}
+}
+
+func TestSayPCMWav(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	out := filepath.Join(t.TempDir(), "hello.wav")
+
+	code, stdout, stderr := runCLI(t, srv, "", "say", "-pcm-wav", "-encoding", "pcm", "-rate", "16000", "-out", out, "你好")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var res sayOutput
+	decodeJSON(t, stdout, &res)
+	b, _ := os.ReadFile(out)
+	if res.Bytes != int64(44+len("你好")) || string(b[:4]) != "RIFF" || string(b[44:]) != "你好" {
+		t.Errorf("output = %+v, audio = %q", res, b)
+	}
+	if cf := (clientFlags{pcmWav: true}); cf.fileExt("pcm") != ".wav" || cf.fileExt("mp3") != ".mp3" {
+		t.Errorf("fileExt = %s, %s", cf.fileExt("pcm"), cf.fileExt("mp3"))
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	cases := [][]string{
+		{},
+		{"unknown"},
+		{"say", "-speed", "9", "你好"},
+		{"say", "-text", "a", "-file", "b"},
+		{"long"},
+		{"long", "status"},
+	}
+	for _, args := range cases {
+		srv := bytettstest.NewServer()
+		if code, _, _ := runCLI(t, srv, "", args...); code != exitUsage {
+			t.Errorf("%v exit code = %d, want %d", args, code, exitUsage)
+		}
+		srv.Close()
+	}
+
+	if code, _, stderr := runCLI(t, nil, "", "say", "你好"); code != exitUsage || !strings.Contains(stderr, "missing credentials") {
+		t.Errorf("missing credentials: code = %d, stderr = %s", code, stderr)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.SetToken("file-token")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	cfg := `{"appid":"appid","token":"file-token","cluster":"cluster","base_url":"` + srv.URL + `"}`
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, _, stderr := runCLI(t, nil, "", "say", "-config", path, "-out", filepath.Join(dir, "out.mp3"), "你好")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+
+	// 命令行参数覆盖配置文件
+	code, _, _ = runCLI(t, nil, "", "say", "-config", path, "-token", "bad", "-retries", "1", "-out", filepath.Join(dir, "out.mp3"), "你好")
+	if code != exitError {
+		t.Errorf("exit code = %d, want %d", code, exitError)
+	}
+}
+
+func TestLong(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	// 每个任务的最后一个状态会一直保持，结果与查询次数无关
+	srv.EnqueueTask(
+		bytettstest.Task{Statuses: []int{bytettstest.TaskRunning}},
+		bytettstest.Task{Statuses: []int{bytettstest.TaskRunning, bytettstest.TaskSuccess}, Audio: []byte("long audio")},
+	)
+	dir := t.TempDir()
+	submit := func() string {
+		t.Helper()
+		code, stdout, stderr := runCLI(t, srv, "", "long", "submit", "-voice", "BV701_streaming", "-rate", "24000", "-text", "长文本")
+		if code != exitOK {
+			t.Fatalf("submit exit code = %d, stderr = %s", code, stderr)
+		}
+		var created struct {
+			TaskId string `json:"task_id"`
+		}
+		decodeJSON(t, stdout, &created)
+		return created.TaskId
+	}
+
+	running := submit()
+	var params map[string]any
+	_ = srv.RequestsTo(bytettstest.PathSubmit)[0].JSON(&params)
+	if params["voice_type"] != "BV701_streaming" || params["sample_rate"] != float64(24000) || params["format"] != "mp3" {
+		t.Errorf("submit params = %v", params)
+	}
+
+	code, stdout, _ := runCLI(t, srv, "", "long", "status", running)
+	var status longOutput
+	decodeJSON(t, stdout, &status)
+	if code != exitOK || status.Status != "running" {
+		t.Errorf("status = %d %s", code, stdout)
+	}
+
+	notReady := filepath.Join(dir, "running.mp3")
+	code, _, _ = runCLI(t, srv, "", "long", "download", "-out", notReady, running)
+	if code != exitError {
+		t.Errorf("download before success exit code = %d", code)
+	}
+	if _, err := os.Stat(notReady); !os.IsNotExist(err) {
+		t.Errorf("download before success should not create the file, stat err = %v", err)
+	}
+
+	done := submit()
+	out := filepath.Join(dir, "long.mp3")
+	code, stdout, stderr := runCLI(t, srv, "", "long", "wait", "-interval", "1ms", "-progress", "-out", out, "-task", done)
+	if code != exitOK {
+		t.Fatalf("wait exit code = %d, stderr = %s", code, stderr)
+	}
+	var waited longOutput
+	decodeJSON(t, stdout, &waited)
+	if waited.Status != "success" || waited.Output != out || waited.Bytes != int64(len("long audio")) || waited.ExpiresAt == "" {
+		t.Errorf("wait output = %s", stdout)
+	}
+	if b, _ := os.ReadFile(out); string(b) != "long audio" {
+		t.Errorf("audio = %q", b)
+	}
+	if !strings.Contains(stderr, `"status":"success"`) {
+		t.Errorf("progress = %s", stderr)
+	}
+
+	downloaded := filepath.Join(dir, "a.mp3")
+	code, stdout, stderr = runCLI(t, srv, "", "long", "download", "-out", downloaded, done)
+	if code != exitOK {
+		t.Fatalf("download exit code = %d, stderr = %s", code, stderr)
+	}
+	if b, _ := os.ReadFile(downloaded); string(b) != "long audio" {
+		t.Errorf("downloaded audio = %q", b)
+	}
+}
+
+func TestVoices(t *testing.T) {
+	code, stdout, _ := runCLI(t, nil, "", "voices", "-language", "en")
+	var voices []byteTts.Voice
+	decodeJSON(t, stdout, &voices)
+	if code != exitOK || len(voices) == 0 {
+		t.Fatalf("exit code = %d, voices = %v", code, voices)
+	}
+	for _, v := range voices {
+		if !v.SupportsLanguage("en") {
+			t.Errorf("voice %s languages = %v", v.VoiceType, v.Languages)
+		}
+	}
+
+	code, stdout, _ = runCLI(t, nil, "", "voices", "-emotion", "narrator")
+	voices = nil
+	decodeJSON(t, stdout, &voices)
+	if code != exitOK || len(voices) == 0 {
+		t.Fatalf("exit code = %d, voices = %v", code, voices)
+	}
+	for _, v := range voices {
+		if !v.SupportsEmotion("narrator") {
+			t.Errorf("voice %s emotions = %v", v.VoiceType, v.Emotions)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(bytettstest.Response{Code: 3011, Message: "invalid text"})
+	dir := t.TempDir()
+	input := strings.Join([]string{
+		`{"id":"welcome","text":"欢迎致电"}`,
+		``,
+		`{"text":"请按1","voice_type":"BV700_streaming","encoding":"pcm"}`,
+		`{"id":"bad","text":"","output":"x.mp3"}`,
+	}, "\n")
+
+	code, stdout, _ := runCLI(t, srv, input, "batch", "-concurrency", "1", "-retries", "1", "-out-dir", dir)
+	if code != exitError {
+		t.Errorf("exit code = %d, want %d", code, exitError)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output lines = %d: %s", len(lines), stdout)
+	}
+	var results []batchResult
+	for _, l := range lines {
+		var res batchResult
+		decodeJSON(t, l, &res)
+		results = append(results, res)
+	}
+
+	// 第一条请求返回预设的错误，其余使用默认响应
+	if results[0].Line != 1 || results[0].Error == nil || results[0].Error.Code != 3011 {
+		t.Errorf("result 0 = %+v", results[0])
+	}
+	if results[1].Line != 3 || results[1].Error != nil || results[1].Output != filepath.Join(dir, "3.pcm") {
+		t.Errorf("result 1 = %+v", results[1])
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "3.pcm")); string(b) != "请按1" {
+		t.Errorf("audio = %q", b)
+	}
+	if results[2].ID != "bad" || results[2].Error == nil {
+		t.Errorf("result 2 = %+v", results[2])
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+	"github.com/zmexing/go-byte-tts/subtitle"
+)
+
+// sayOutput say 命令的输出
+type sayOutput struct {
+	Output    string `json:"output"`
+	Bytes     int64  `json:"bytes"`
+	Encoding  string `json:"encoding"`
+	VoiceType string `json:"voice_type"`
+	TextBytes int    `json:"text_bytes"`
+	Subtitle  string `json:"subtitle,omitempty"`
+	Cues      int    `json:"cues,omitempty"`
+}
+
+func runSay(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "say")
+	var cf clientFlags
+	var af audioFlags
+	var tf textFlags
+	cf.register(fs)
+	af.register(fs)
+	tf.register(fs)
+	out := fs.String("out", "", "输出的音频文件，默认为 output 加上编码对应的扩展名")
+	subtitlePath := fs.String("subtitle", "", "同时输出字幕文件，按扩展名使用 .srt / .vtt 格式，.json 为字词时间轴")
+	var opts subtitle.Options
+	fs.IntVar(&opts.MaxLineLength, "subtitle-line-length", 0, "字幕每行最多的字符数，默认为20")
+	fs.IntVar(&opts.MaxLines, "subtitle-lines", 0, "每条字幕最多的行数，默认为2")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *subtitlePath != "" && !supportedSubtitle(*subtitlePath) {
+		return usageErrorf("-subtitle %s: unsupported extension, use .srt, .vtt or .json", *subtitlePath)
+	}
+
+	text, err := tf.read(e, fs.Args())
+	if err != nil {
+		return err
+	}
+	req := af.request(text)
+	if err := req.Validate(); err != nil {
+		return usageErrorf("%v", err)
+	}
+	if *out == "" {
+		*out = "output" + cf.fileExt(af.encoding)
+	}
+
+	tts, ctx, cancel, err := cf.client(ctx, e)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	res := sayOutput{
+		Output:    *out,
+		Encoding:  af.encoding,
+		VoiceType: af.voice,
+		TextBytes: len(text),
+	}
+	if *subtitlePath == "" {
+		res.Bytes, err = synthesizeFile(ctx, tts, req, *out)
+		if err != nil {
+			return err
+		}
+		return printJSON(e.stdout, res)
+	}
+
+	params, err := req.Params()
+	if err != nil {
+		return err
+	}
+	var timeline *byteTts.Timeline
+	res.Bytes, err = writeFile(*out, func(w io.Writer) error {
+		timeline, err = tts.TextToJoinVoiceTimeline(ctx, params, w)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	res.Subtitle = *subtitlePath
+	res.Cues, err = writeSubtitle(*subtitlePath, timeline, opts)
+	if err != nil {
+		return err
+	}
+	return printJSON(e.stdout, res)
+}
+
+func supportedSubtitle(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt", ".vtt", ".json":
+		return true
+	}
+	return false
+}
+
+// writeSubtitle 按扩展名写入字幕或字词时间轴，返回字幕条数
+func writeSubtitle(path string, timeline *byteTts.Timeline, opts subtitle.Options) (int, error) {
+	cues := subtitle.Cues(timeline.Words, opts)
+	_, err := writeFile(path, func(w io.Writer) error {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".srt":
+			return subtitle.WriteSRT(w, cues)
+		case ".vtt":
+			return subtitle.WriteVTT(w, cues)
+		default:
+			return subtitle.WriteJSON(w, timeline.Words)
+		}
+	})
+	return len(cues), err
+}
+
+// synthesizeFile 合成并写入文件，超长文本自动分片，失败时删除不完整的文件
+func synthesizeFile(ctx context.Context, tts byteTts.GoTTSInter, req *byteTts.SynthesisRequest, path string) (int64, error) {
+	return writeFile(path, func(w io.Writer) error {
+		return tts.TextToJoinVoiceRequestWriter(ctx, req, w)
+	})
+}
+
+// writeFile 创建文件并由 write 写入，返回文件大小，失败时删除不完整的文件
+func writeFile(path string, write func(w io.Writer) error) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	byteTts "github.com/zmexing/go-byte-tts"
+)
+
+// runVoices 列出内置音色目录中的音色，完整列表参见火山引擎文档
+func runVoices(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet(e, "voices")
+	language := fs.String("language", "", "只列出支持该语言的音色，例如 zh / en")
+	gender := fs.String("gender", "", "只列出该性别的音色：female / male")
+	emotion := fs.String("emotion", "", "只列出支持该情感的音色，例如 happy")
+	cluster := fs.String("cluster", "", "只列出属于该业务集群的音色")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	voices := make([]byteTts.Voice, 0)
+	for _, v := range byteTts.Voices() {
+		if *language != "" && !v.SupportsLanguage(*language) {
+			continue
+		}
+		if *gender != "" && v.Gender != *gender {
+			continue
+		}
+		if *emotion != "" && !v.SupportsEmotion(*emotion) {
+			continue
+		}
+		if *cluster != "" && !v.InCluster(*cluster) {
+			continue
+		}
+		voices = append(voices, v)
+	}
+	return printJSON(e.stdout, voices)
+}
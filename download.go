@@ -0,0 +1,225 @@
+package go_byte_tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 下载中断后的最少续传次数，重试策略的次数更多时使用重试策略
+	minDownloadAttempts = 3
+	// 判断 403 原因时最多读取的响应内容
+	maxErrorBodyBytes = 4 << 10
+)
+
+// ErrAudioURLExpired 长文本音频URL已经过期，需要重新创建任务
+var ErrAudioURLExpired = errors.New("tts: audio url expired")
+
+// DownloadLongTextAudio 下载长文本任务合成的音频并写入 w
+// 使用 GoTTS 配置的 http 客户端，下载中断后通过 Range 请求从中断位置继续下载
+// 音频URL过期后直接返回 [ErrAudioURLExpired]，签名错误、没有权限等其他 403 返回包含状态码的 *APIError
+func (g *GoTTS) DownloadLongTextAudio(ctx context.Context, result *TtsAsyncQueryRep, w io.Writer) error {
+	if result == nil || result.AudioUrl == "" {
+		return errors.New("audio url cannot be empty")
+	}
+
+	attempts := g.retryPolicy.MaxAttempts
+	if attempts < minDownloadAttempts {
+		attempts = minDownloadAttempts
+	}
+
+	var written int64
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(g.retryPolicy.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		if expiresAt := result.ExpiresAt(); !expiresAt.IsZero() && time.Now().After(expiresAt) {
+			return fmt.Errorf("task %s audio url expired at %s: %w", result.TaskId, expiresAt.Format(time.RFC3339), ErrAudioURLExpired)
+		}
+
+		var n int64
+		n, err = g.downloadRange(ctx, result, written, w)
+		written += n
+		if err == nil {
+			return nil
+		}
+		var fatal *downloadFatalError
+		if errors.As(err, &fatal) || ctx.Err() != nil || !g.retryPolicy.retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// downloadFatalError 不需要续传的下载错误，例如写入失败、响应内容不是音频
+type downloadFatalError struct {
+	err error
+}
+
+func (e *downloadFatalError) Error() string { return e.err.Error() }
+func (e *downloadFatalError) Unwrap() error { return e.err }
+
+// downloadRange 从 offset 开始下载，返回本次写入的字节数
+func (g *GoTTS) downloadRange(ctx context.Context, result *TtsAsyncQueryRep, offset int64, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.AudioUrl, nil)
+	if err != nil {
+		return 0, &downloadFatalError{fmt.Errorf("http new request error: %w", err)}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// 服务端不支持 Range 时跳过已经写入的部分
+		if offset > 0 {
+			if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				return 0, fmt.Errorf("skip downloaded audio error: %w", err)
+			}
+		}
+	case http.StatusPartialContent:
+		if start := contentRangeStart(resp.Header.Get("Content-Range")); start != offset {
+			return 0, &downloadFatalError{fmt.Errorf("unexpected Content-Range %q, want start %d", resp.Header.Get("Content-Range"), offset)}
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 上一次下载已经写入全部内容
+		if offset > 0 && contentRangeTotal(resp.Header.Get("Content-Range")) == offset {
+			return 0, nil
+		}
+		return 0, &downloadFatalError{fmt.Errorf("download audio failed: %s", resp.Status)}
+	case http.StatusForbidden:
+		// 对象存储的签名过期时返回 403，签名错误、没有权限时也返回 403，这些错误重新查询任务也无法解决
+		if expired403(result, resp) {
+			return 0, &downloadFatalError{fmt.Errorf("download audio failed: %s: %w", resp.Status, ErrAudioURLExpired)}
+		}
+		return 0, &downloadFatalError{fmt.Errorf("download audio failed: %w", newAPIError(resp.StatusCode, 0, "", ""))}
+	default:
+		apiErr := newAPIError(resp.StatusCode, 0, "", "")
+		if !apiErr.Retryable() {
+			return 0, &downloadFatalError{fmt.Errorf("download audio failed: %w", apiErr)}
+		}
+		return 0, fmt.Errorf("download audio failed: %w", apiErr)
+	}
+
+	if err := checkAudioContentType(resp.Header.Get("Content-Type")); err != nil {
+		return 0, &downloadFatalError{err}
+	}
+
+	n, err := io.Copy(downloadWriter{w}, resp.Body)
+	if err != nil {
+		var writeErr *downloadWriteError
+		if errors.As(err, &writeErr) {
+			return n, &downloadFatalError{fmt.Errorf("error while writing audio: %w", writeErr.err)}
+		}
+		return n, fmt.Errorf("error while reading audio: %w", err)
+	}
+	return n, nil
+}
+
+// expired403 403 响应是否由URL过期引起：任务的过期时间已过，或者响应内容、响应头提示签名过期
+func expired403(result *TtsAsyncQueryRep, resp *http.Response) bool {
+	if expiresAt := result.ExpiresAt(); !expiresAt.IsZero() && !time.Now().Before(expiresAt) {
+		return true
+	}
+	for _, values := range resp.Header {
+		for _, v := range values {
+			if mentionsExpired(v) {
+				return true
+			}
+		}
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return mentionsExpired(string(body))
+}
+
+// mentionsExpired 对象存储的过期错误，例如 "Request has expired"、"ExpiredToken"、"SignatureExpired"
+func mentionsExpired(s string) bool {
+	return strings.Contains(strings.ToLower(s), "expired")
+}
+
+// downloadWriter 标记写入目标时的错误，与读取响应时的网络错误区分
+type downloadWriter struct {
+	w io.Writer
+}
+
+func (d downloadWriter) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	if err != nil {
+		return n, &downloadWriteError{err}
+	}
+	return n, nil
+}
+
+// downloadWriteError 写入目标时的错误
+type downloadWriteError struct {
+	err error
+}
+
+func (e *downloadWriteError) Error() string { return e.err.Error() }
+
+// checkAudioContentType 校验响应是否为音频内容，出错时对象存储通常返回 xml / json / html
+func checkAudioContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid Content-Type %q: %w", contentType, err)
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "audio/"),
+		mediaType == "application/octet-stream",
+		mediaType == "binary/octet-stream",
+		mediaType == "application/ogg":
+		return nil
+	}
+	return fmt.Errorf("unexpected Content-Type %q for audio", contentType)
+}
+
+// contentRangeStart 解析 "bytes 100-199/200" 中的起始位置
+func contentRangeStart(contentRange string) int64 {
+	spec := strings.TrimPrefix(contentRange, "bytes ")
+	dash := strings.IndexByte(spec, '-')
+	if dash < 0 {
+		return -1
+	}
+	start, err := strconv.ParseInt(spec[:dash], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
+
+// contentRangeTotal 解析 "bytes */200" 中的总长度
+func contentRangeTotal(contentRange string) int64 {
+	slash := strings.LastIndexByte(contentRange, '/')
+	if slash < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(contentRange[slash+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
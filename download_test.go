@@ -0,0 +1,139 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+// newAudioServer 提供支持 Range 的音频下载，interrupt 时首次请求只返回一半内容后断开连接
+// bytettstest 的音频地址不会中途断开连接，也只返回 audio/mpeg，续传和 Content-Type 的测试使用这个服务
+func newAudioServer(t *testing.T, audio []byte, contentType string, interrupt bool) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		first := len(ranges) == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", contentType)
+		if interrupt && first {
+			w.Header().Set("Content-Length", strconv.Itoa(len(audio)))
+			_, _ = w.Write(audio[:len(audio)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "audio.mp3", time.Time{}, bytes.NewReader(audio))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &ranges
+}
+
+func TestDownloadLongTextAudioResume(t *testing.T) {
+	audio := bytes.Repeat([]byte("0123456789"), 1000)
+	srv, ranges := newAudioServer(t, audio, "audio/mpeg", true)
+	tts := newOfflineTTS(t)
+
+	var buf bytes.Buffer
+	result := &TtsAsyncQueryRep{TaskId: "task-1", AudioUrl: srv.URL, UrlExpireTime: int(time.Now().Add(time.Hour).Unix())}
+	if err := tts.DownloadLongTextAudio(context.Background(), result, &buf); err != nil {
+		t.Fatalf("DownloadLongTextAudio err = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), audio) {
+		t.Errorf("downloaded %d bytes, want %d", buf.Len(), len(audio))
+	}
+	if len(*ranges) != 2 || (*ranges)[0] != "" || (*ranges)[1] != "bytes=5000-" {
+		t.Errorf("ranges = %q", *ranges)
+	}
+}
+
+func TestDownloadLongTextAudioExpired(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+	taskId := submitTask(t, tts, srv, bytettstest.Task{ExpireTime: time.Now().Add(-time.Minute)})
+	result, err := tts.LongTextToVoiceId(taskId)
+	if err != nil {
+		t.Fatalf("LongTextToVoiceId err = %v", err)
+	}
+
+	err = tts.DownloadLongTextAudio(context.Background(), result, &bytes.Buffer{})
+	if !errors.Is(err, ErrAudioURLExpired) {
+		t.Fatalf("err = %v, want ErrAudioURLExpired", err)
+	}
+	if n := len(srv.RequestsTo(bytettstest.PathAudioPrefix + taskId)); n != 0 {
+		t.Errorf("expired url should not be requested, requests = %d", n)
+	}
+}
+
+func TestDownloadLongTextAudioContentType(t *testing.T) {
+	srv, _ := newAudioServer(t, []byte("<Error>AccessDenied</Error>"), "application/xml", false)
+	tts := newOfflineTTS(t)
+
+	var buf bytes.Buffer
+	err := tts.DownloadLongTextAudio(context.Background(), &TtsAsyncQueryRep{AudioUrl: srv.URL}, &buf)
+	if err == nil {
+		t.Fatal("xml response should be rejected")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written, got %q", buf.String())
+	}
+}
+
+func TestDownloadLongTextAudioStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	tts := newOfflineTTS(t)
+
+	err := tts.DownloadLongTextAudio(context.Background(), &TtsAsyncQueryRep{AudioUrl: srv.URL}, &bytes.Buffer{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusNotFound {
+		t.Fatalf("err = %v, want 404 APIError", err)
+	}
+}
+
+func TestDownloadLongTextAudioForbidden(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		expires time.Duration
+		expired bool
+	}{
+		{"signature mismatch", "<Error><Code>SignatureDoesNotMatch</Code></Error>", time.Hour, false},
+		{"access denied", "<Error><Code>AccessDenied</Code></Error>", 0, false},
+		{"request expired", "<Error><Code>AccessDenied</Code><Message>Request has expired</Message></Error>", time.Hour, true},
+	}
+	for _, c := range cases {
+		body := c.body
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(body))
+		}))
+		tts := newOfflineTTS(t)
+
+		result := &TtsAsyncQueryRep{AudioUrl: srv.URL}
+		if c.expires != 0 {
+			result.UrlExpireTime = int(time.Now().Add(c.expires).Unix())
+		}
+		err := tts.DownloadLongTextAudio(context.Background(), result, &bytes.Buffer{})
+		var apiErr *APIError
+		switch {
+		case c.expired && !errors.Is(err, ErrAudioURLExpired):
+			t.Errorf("%s: err = %v, want ErrAudioURLExpired", c.name, err)
+		case !c.expired && (errors.Is(err, ErrAudioURLExpired) || !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusForbidden):
+			t.Errorf("%s: err = %v, want 403 APIError", c.name, err)
+		}
+		srv.Close()
+	}
+}
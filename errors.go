@@ -0,0 +1,160 @@
+package go_byte_tts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// 短文本语音合成成功的返回码
+	codeSuccess = 3000
+	// 长文本语音合成成功的返回码，成功时不返回 code 字段
+	codeAsyncSuccess = 0
+)
+
+// ErrorKind 接口错误分类
+type ErrorKind int
+
+const (
+	KindUnknown      ErrorKind = iota // 未知错误
+	KindInvalidParam                  // 请求参数错误，例如音色不存在
+	KindInvalidText                   // 文本为空、超长或与语种不匹配
+	KindAuth                          // 鉴权失败
+	KindQuota                         // 超出并发、频率或用量限制
+	KindServerBusy                    // 服务繁忙
+	KindServerError                   // 服务内部错误、超时或链路异常
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindInvalidParam:
+		return "invalid_param"
+	case KindInvalidText:
+		return "invalid_text"
+	case KindAuth:
+		return "auth"
+	case KindQuota:
+		return "quota"
+	case KindServerBusy:
+		return "server_busy"
+	case KindServerError:
+		return "server_error"
+	default:
+		return "unknown"
+	}
+}
+
+// 可以通过 errors.Is 判断 [APIError] 的分类
+var (
+	ErrRetryable    = errors.New("tts: retryable error")
+	ErrInvalidParam = errors.New("tts: invalid parameter")
+	ErrInvalidText  = errors.New("tts: invalid text")
+	ErrAuth         = errors.New("tts: authentication failed")
+	ErrQuota        = errors.New("tts: quota exceeded")
+	ErrServerBusy   = errors.New("tts: server busy")
+	ErrServerError  = errors.New("tts: server error")
+	// ErrEmptyAudio 接口返回成功但没有音频数据
+	ErrEmptyAudio = errors.New("tts: empty audio data")
+)
+
+var kindErrors = map[ErrorKind]error{
+	KindInvalidParam: ErrInvalidParam,
+	KindInvalidText:  ErrInvalidText,
+	KindAuth:         ErrAuth,
+	KindQuota:        ErrQuota,
+	KindServerBusy:   ErrServerBusy,
+	KindServerError:  ErrServerError,
+}
+
+// 短文本语音合成返回码分类
+var ttsCodeKinds = map[int]ErrorKind{
+	3001: KindInvalidParam, // 无效的请求
+	3003: KindQuota,        // 并发超限
+	3005: KindServerBusy,   // 后端服务忙
+	3006: KindServerError,  // 服务中断
+	3010: KindInvalidText,  // 文本长度超限
+	3011: KindInvalidText,  // 无效文本
+	3030: KindServerError,  // 处理超时
+	3031: KindServerError,  // 处理错误
+	3032: KindServerError,  // 等待获取音频超时
+	3040: KindServerError,  // 后端链路连接错误
+	3050: KindInvalidParam, // 音色不存在
+}
+
+// APIError 接口返回的错误
+type APIError struct {
+	Code       int       // 接口返回码
+	Message    string    // 接口返回的错误信息
+	ReqID      string    // 请求标识
+	HTTPStatus int       // HTTP 状态码
+	Kind       ErrorKind // 错误分类
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("tts api error: code=%d, message=%s, reqid=%s, http_status=%d, kind=%s",
+		e.Code, e.Message, e.ReqID, e.HTTPStatus, e.Kind)
+}
+
+// Retryable 是否为可重试的临时错误
+func (e *APIError) Retryable() bool {
+	switch e.Kind {
+	case KindQuota, KindServerBusy, KindServerError:
+		return true
+	}
+	return false
+}
+
+// Is 支持 errors.Is(err, ErrQuota) 等分类判断
+func (e *APIError) Is(target error) bool {
+	if target == ErrRetryable {
+		return e.Retryable()
+	}
+	kindErr, ok := kindErrors[e.Kind]
+	return ok && kindErr == target
+}
+
+// newAPIError 根据返回码和 HTTP 状态码生成错误
+func newAPIError(httpStatus, code int, message, reqid string) *APIError {
+	kind, ok := ttsCodeKinds[code]
+	if !ok {
+		kind = classifyAsyncCode(code)
+	}
+	if kind == KindUnknown {
+		kind = classifyHTTPStatus(httpStatus)
+	}
+	if message == "" && httpStatus != http.StatusOK {
+		message = http.StatusText(httpStatus)
+	}
+	return &APIError{
+		Code:       code,
+		Message:    message,
+		ReqID:      reqid,
+		HTTPStatus: httpStatus,
+		Kind:       kind,
+	}
+}
+
+// classifyAsyncCode 长文本语音合成的返回码为5位数，前3位与 HTTP 状态码含义一致，例如 40000 / 42900 / 50000
+func classifyAsyncCode(code int) ErrorKind {
+	if code < 10000 || code > 99999 {
+		return KindUnknown
+	}
+	return classifyHTTPStatus(code / 100)
+}
+
+func classifyHTTPStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return KindAuth
+	case status == http.StatusTooManyRequests:
+		return KindQuota
+	case status == http.StatusServiceUnavailable:
+		return KindServerBusy
+	case status >= http.StatusInternalServerError:
+		return KindServerError
+	case status >= http.StatusBadRequest:
+		return KindInvalidParam
+	}
+	return KindUnknown
+}
@@ -0,0 +1,52 @@
+package go_byte_tts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIErrorKind(t *testing.T) {
+	cases := []struct {
+		status    int
+		code      int
+		kind      ErrorKind
+		retryable bool
+		target    error
+	}{
+		{http.StatusOK, 3001, KindInvalidParam, false, ErrInvalidParam},
+		{http.StatusOK, 3003, KindQuota, true, ErrQuota},
+		{http.StatusOK, 3005, KindServerBusy, true, ErrServerBusy},
+		{http.StatusOK, 3011, KindInvalidText, false, ErrInvalidText},
+		{http.StatusOK, 3031, KindServerError, true, ErrServerError},
+		{http.StatusOK, 40000, KindInvalidParam, false, ErrInvalidParam},
+		{http.StatusOK, 42900, KindQuota, true, ErrQuota},
+		{http.StatusOK, 50000, KindServerError, true, ErrServerError},
+		{http.StatusUnauthorized, 0, KindAuth, false, ErrAuth},
+		{http.StatusForbidden, 3999, KindAuth, false, ErrAuth},
+		{http.StatusTooManyRequests, 0, KindQuota, true, ErrQuota},
+		{http.StatusServiceUnavailable, 0, KindServerBusy, true, ErrServerBusy},
+	}
+	for _, c := range cases {
+		e := newAPIError(c.status, c.code, "", "reqid")
+		if e.Kind != c.kind {
+			t.Errorf("status=%d code=%d kind = %s, want %s", c.status, c.code, e.Kind, c.kind)
+		}
+		if e.Retryable() != c.retryable {
+			t.Errorf("status=%d code=%d retryable = %v", c.status, c.code, e.Retryable())
+		}
+
+		err := fmt.Errorf("wrapped: %w", e)
+		if !errors.Is(err, c.target) {
+			t.Errorf("status=%d code=%d errors.Is(%v) = false", c.status, c.code, c.target)
+		}
+		if errors.Is(err, ErrRetryable) != c.retryable {
+			t.Errorf("status=%d code=%d errors.Is(ErrRetryable) = %v", c.status, c.code, !c.retryable)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != c.code || apiErr.HTTPStatus != c.status {
+			t.Errorf("errors.As = %+v", apiErr)
+		}
+	}
+}
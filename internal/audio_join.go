@@ -0,0 +1,289 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AudioJoiner 按音频格式依次拼接多个完整的音频分片并写入 w
+type AudioJoiner interface {
+	// Append 追加一个完整的音频分片
+	Append(chunk []byte) error
+	// Close 写入剩余内容，不会关闭 w
+	Close() error
+}
+
+// NewAudioJoiner 根据 audio.encoding 创建拼接器
+// wav 合并头部，mp3 去掉各分片的标签和 Xing 信息帧，ogg_opus 重新编号页面，pcm 和其他格式直接拼接
+func NewAudioJoiner(encoding string, w io.Writer) AudioJoiner {
+	switch encoding {
+	case "wav":
+		return &wavJoiner{w: w}
+	case "mp3":
+		return &mp3Joiner{w: w}
+	case "ogg_opus":
+		return &oggJoiner{w: w}
+	default:
+		return &rawJoiner{w: w}
+	}
+}
+
+// rawJoiner 直接拼接，用于 pcm
+type rawJoiner struct {
+	w io.Writer
+}
+
+func (j *rawJoiner) Append(chunk []byte) error {
+	_, err := j.w.Write(chunk)
+	return err
+}
+
+func (j *rawJoiner) Close() error {
+	return nil
+}
+
+// mp3Joiner 去掉每个分片的 ID3 标签和 Xing / Info / VBRI 信息帧后拼接
+type mp3Joiner struct {
+	w io.Writer
+}
+
+func (j *mp3Joiner) Append(chunk []byte) error {
+	_, err := j.w.Write(StripMp3Tags(chunk))
+	return err
+}
+
+func (j *mp3Joiner) Close() error {
+	return nil
+}
+
+// wavJoiner 合并所有分片的 data 块，只写入一个头部
+// w 支持 Seek 时边写边拼接，最后回写头部的长度；否则缓存全部数据，在 Close 时写入
+type wavJoiner struct {
+	w      io.Writer
+	format *WavFormat
+	seeker io.WriteSeeker
+	start  int64 // 头部在 w 中的位置
+	size   uint64
+	buf    []byte
+}
+
+func (j *wavJoiner) Append(chunk []byte) error {
+	format, data, err := ParseWav(chunk)
+	if err != nil {
+		return err
+	}
+
+	if j.format == nil {
+		j.format = format
+		if ws, ok := j.w.(io.WriteSeeker); ok {
+			if pos, err := ws.Seek(0, io.SeekCurrent); err == nil {
+				j.seeker = ws
+				j.start = pos
+				if _, err := ws.Write(WavHeader(format, 0)); err != nil {
+					return err
+				}
+			}
+		}
+	} else if !j.format.Equal(format) {
+		return fmt.Errorf("wav chunk format mismatch: %s != %s", format, j.format)
+	}
+
+	j.size += uint64(len(data))
+	if j.seeker == nil {
+		j.buf = append(j.buf, data...)
+		return nil
+	}
+	_, err = j.seeker.Write(data)
+	return err
+}
+
+func (j *wavJoiner) Close() error {
+	if j.format == nil {
+		return nil
+	}
+
+	header := WavHeader(j.format, wavDataSize(j.size))
+
+	if j.seeker == nil {
+		if _, err := j.w.Write(header); err != nil {
+			return err
+		}
+		_, err := j.w.Write(j.buf)
+		return err
+	}
+
+	return rewriteAt(j.seeker, j.start, header)
+}
+
+// rewriteAt 在 pos 处重新写入 b，之后回到原来的位置
+func rewriteAt(ws io.WriteSeeker, pos int64, b []byte) error {
+	end, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := ws.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := ws.Write(b); err != nil {
+		return err
+	}
+	_, err = ws.Seek(end, io.SeekStart)
+	return err
+}
+
+// wavDataSize data 块长度，超出 uint32 时取最大值
+func wavDataSize(size uint64) uint32 {
+	if size < wavUnknownSize {
+		return uint32(size)
+	}
+	return wavUnknownSize
+}
+
+// NewPCMWavJoiner 拼接 pcm 分片并添加 WAV 头部，format 为 pcm 的格式
+// w 支持 Seek 时最后回写头部的长度；否则写入长度未知的流式头部，边拼接边写入，不缓存音频
+func NewPCMWavJoiner(format *WavFormat, w io.Writer) AudioJoiner {
+	return &pcmWavJoiner{w: w, format: format}
+}
+
+type pcmWavJoiner struct {
+	w       io.Writer
+	format  *WavFormat
+	seeker  io.WriteSeeker
+	start   int64
+	size    uint64
+	started bool
+}
+
+// writeHeader 写入头部，w 支持 Seek 时先写入长度为0的头部，Close 时回写
+func (j *pcmWavJoiner) writeHeader() error {
+	j.started = true
+	if ws, ok := j.w.(io.WriteSeeker); ok {
+		if pos, err := ws.Seek(0, io.SeekCurrent); err == nil {
+			j.seeker = ws
+			j.start = pos
+			_, err := ws.Write(WavHeader(j.format, 0))
+			return err
+		}
+	}
+	_, err := j.w.Write(WavHeader(j.format, wavUnknownSize))
+	return err
+}
+
+func (j *pcmWavJoiner) Append(chunk []byte) error {
+	if !j.started {
+		if err := j.writeHeader(); err != nil {
+			return err
+		}
+	}
+	j.size += uint64(len(chunk))
+	_, err := j.w.Write(chunk)
+	return err
+}
+
+func (j *pcmWavJoiner) Close() error {
+	if !j.started {
+		if err := j.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if j.seeker == nil {
+		return nil
+	}
+	return rewriteAt(j.seeker, j.start, WavHeader(j.format, wavDataSize(j.size)))
+}
+
+// oggJoiner 将多个 Ogg Opus 分片合并为一个逻辑流
+// 后续分片去掉 OpusHead / OpusTags 头部页面，统一流序列号，页面序号连续递增，granule 累加
+// 拼接后只有第一个分片的 pre-skip 会被丢弃，后续分片的 pre-skip 样本仍会被解码输出，因此 granule 按原值累加
+type oggJoiner struct {
+	w        io.Writer
+	serial   uint32
+	sequence uint32
+	channels int
+	base     int64    // 之前分片累计的 granule
+	last     int64    // 已输出页面的最后一个 granule
+	pending  *OggPage // 暂存的最后一页，Close 时标记为流结束
+	started  bool
+}
+
+func (j *oggJoiner) Append(chunk []byte) error {
+	pages, err := ParseOggPages(chunk)
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return errors.New("invalid ogg: no pages")
+	}
+
+	channels, _, ok := OpusHead(pages[0])
+	if !ok {
+		return errors.New("invalid ogg: missing OpusHead")
+	}
+
+	if !j.started {
+		j.started = true
+		j.serial = pages[0].Serial
+		j.channels = channels
+		for _, p := range pages {
+			if err := j.emit(p); err != nil {
+				return err
+			}
+		}
+		j.base = j.last
+		return nil
+	}
+
+	if channels != j.channels {
+		return fmt.Errorf("ogg chunk channels mismatch: %d != %d", channels, j.channels)
+	}
+
+	// 头部页面的 granule 为0，音频页面从第一个 granule 不为0的页面开始
+	audio := false
+	for _, p := range pages {
+		if !audio && p.Granule == 0 {
+			continue
+		}
+		audio = true
+		if p.Granule != -1 {
+			p.Granule += j.base
+		}
+		if err := j.emit(p); err != nil {
+			return err
+		}
+	}
+	j.base = j.last
+	return nil
+}
+
+// emit 输出上一个暂存的页面，并暂存当前页面
+func (j *oggJoiner) emit(p *OggPage) error {
+	p.Serial = j.serial
+	p.Sequence = j.sequence
+	j.sequence++
+	p.HeaderType &^= OggFlagEOS
+	if p.Sequence != 0 {
+		p.HeaderType &^= OggFlagBOS
+	}
+	if p.Granule != -1 {
+		j.last = p.Granule
+	}
+
+	if j.pending != nil {
+		if _, err := j.w.Write(j.pending.Bytes()); err != nil {
+			return err
+		}
+	}
+	j.pending = p
+	return nil
+}
+
+func (j *oggJoiner) Close() error {
+	if j.pending == nil {
+		return nil
+	}
+	j.pending.HeaderType |= OggFlagEOS
+	_, err := j.w.Write(j.pending.Bytes())
+	j.pending = nil
+	return err
+}
@@ -0,0 +1,320 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func testWavFormat() *WavFormat {
+	return &WavFormat{AudioFormat: 1, Channels: 1, SampleRate: 24000, ByteRate: 48000, BlockAlign: 2, BitsPerSample: 16}
+}
+
+func testWav(f *WavFormat, data []byte) []byte {
+	return append(WavHeader(f, uint32(len(data))), data...)
+}
+
+func TestParseWav(t *testing.T) {
+	f := testWavFormat()
+	format, data, err := ParseWav(testWav(f, []byte{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !format.Equal(f) || !bytes.Equal(data, []byte{1, 2, 3, 4}) {
+		t.Fatalf("unexpected wav: %s %v", format, data)
+	}
+
+	// 流式输出的 data 长度为0时读取到末尾
+	b := append(WavHeader(f, 0), 5, 6)
+	if _, data, err := ParseWav(b); err != nil || !bytes.Equal(data, []byte{5, 6}) {
+		t.Fatalf("unexpected data: %v %v", data, err)
+	}
+
+	if _, _, err := ParseWav([]byte("not a wav file")); err == nil {
+		t.Fatal("expected error for invalid wav")
+	}
+}
+
+func TestWavJoiner(t *testing.T) {
+	f := testWavFormat()
+	chunks := [][]byte{testWav(f, []byte{1, 2}), testWav(f, []byte{3, 4, 5, 6})}
+	want := testWav(f, []byte{1, 2, 3, 4, 5, 6})
+
+	// 不支持 Seek 的 writer
+	var buf bytes.Buffer
+	j := NewAudioJoiner("wav", &buf)
+	for _, c := range chunks {
+		if err := j.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("got %v, want %v", buf.Bytes(), want)
+	}
+
+	// 文件支持 Seek，回写头部长度
+	file, err := os.CreateTemp(t.TempDir(), "join-*.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	j = NewAudioJoiner("wav", file)
+	for _, c := range chunks {
+		if err := j.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestWavJoinerFormatMismatch(t *testing.T) {
+	other := testWavFormat()
+	other.SampleRate = 16000
+
+	j := NewAudioJoiner("wav", &bytes.Buffer{})
+	if err := j.Append(testWav(testWavFormat(), []byte{1, 2})); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Append(testWav(other, []byte{3, 4})); err == nil {
+		t.Fatal("expected error for mismatched format")
+	}
+}
+
+// testMp3Frame MPEG1 Layer III 128kbps 44100Hz 单声道帧，payload 写在 side information 之后
+func testMp3Frame(payload string) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	copy(frame[4+17:], payload)
+	return frame
+}
+
+func TestParseMp3Frame(t *testing.T) {
+	f, err := ParseMp3Frame(testMp3Frame(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Version != 1 || f.Bitrate != 128 || f.SampleRate != 44100 || f.Channels != 1 || f.Size != 417 {
+		t.Fatalf("unexpected frame: %+v", f)
+	}
+
+	if _, err := ParseMp3Frame([]byte{0, 1, 2, 3}); err == nil {
+		t.Fatal("expected error for invalid sync")
+	}
+}
+
+func TestMp3Joiner(t *testing.T) {
+	xing := testMp3Frame("Xing\x00\x00\x00\x03\x00\x00\x00\x01\x00\x00\x01\xa1")
+	id3 := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 2, 0, 0}
+	audio1 := testMp3Frame("audio1")
+	audio2 := testMp3Frame("audio2")
+
+	h := ParseMp3VbrHeader(xing, &Mp3Frame{Version: 1, Channels: 1})
+	if h == nil || h.Tag != "Xing" || h.Frames != 1 || h.Bytes != 417 {
+		t.Fatalf("unexpected vbr header: %+v", h)
+	}
+
+	var buf bytes.Buffer
+	j := NewAudioJoiner("mp3", &buf)
+	chunk1 := append(append(append([]byte{}, id3...), xing...), audio1...)
+	chunk2 := append(append([]byte{}, xing...), audio2...)
+	for _, c := range [][]byte{chunk1, chunk2} {
+		if err := j.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := append(append([]byte{}, audio1...), audio2...)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("joined mp3 length %d, want %d", buf.Len(), len(want))
+	}
+}
+
+func testOpusHead(channels byte) []byte {
+	head := []byte("OpusHead")
+	head = append(head, 1, channels, 0x38, 0x01)
+	head = appendUint32(head, 24000)
+	return append(head, 0, 0, 0)
+}
+
+func testOggPage(headerType uint8, granule int64, serial, seq uint32, data []byte) *OggPage {
+	return &OggPage{
+		HeaderType: headerType,
+		Granule:    granule,
+		Serial:     serial,
+		Sequence:   seq,
+		Segments:   []byte{byte(len(data))},
+		Data:       data,
+	}
+}
+
+// testOggChunk 生成一个完整的 Ogg Opus 文件：OpusHead、OpusTags 和两个音频页面
+func testOggChunk(serial uint32, audio string) []byte {
+	pages := []*OggPage{
+		testOggPage(OggFlagBOS, 0, serial, 0, testOpusHead(1)),
+		testOggPage(0, 0, serial, 1, []byte("OpusTags")),
+		testOggPage(0, 960, serial, 2, []byte(audio+"-1")),
+		testOggPage(OggFlagEOS, 1920, serial, 3, []byte(audio+"-2")),
+	}
+	var b []byte
+	for _, p := range pages {
+		b = append(b, p.Bytes()...)
+	}
+	return b
+}
+
+func TestParseOggPages(t *testing.T) {
+	chunk := testOggChunk(7, "a")
+	pages, err := ParseOggPages(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 4 || pages[3].Granule != 1920 || string(pages[2].Data) != "a-1" {
+		t.Fatalf("unexpected pages: %+v", pages)
+	}
+	if channels, preSkip, ok := OpusHead(pages[0]); !ok || channels != 1 || preSkip != 312 {
+		t.Fatalf("unexpected OpusHead: %d %d %v", channels, preSkip, ok)
+	}
+
+	// 序列化后与原始数据一致
+	var b []byte
+	for _, p := range pages {
+		b = append(b, p.Bytes()...)
+	}
+	if !bytes.Equal(b, chunk) {
+		t.Fatal("serialized pages differ from input")
+	}
+
+	if _, err := ParseOggPages(chunk[:len(chunk)-1]); err == nil {
+		t.Fatal("expected error for truncated page")
+	}
+}
+
+func TestOggJoiner(t *testing.T) {
+	var buf bytes.Buffer
+	j := NewAudioJoiner("ogg_opus", &buf)
+	for _, c := range [][]byte{testOggChunk(1, "a"), testOggChunk(2, "b")} {
+		if err := j.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := ParseOggPages(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		data    string
+		granule int64
+		flags   uint8
+	}{
+		{"OpusHead", 0, OggFlagBOS},
+		{"OpusTags", 0, 0},
+		{"a-1", 960, 0},
+		{"a-2", 1920, 0},
+		{"b-1", 2880, 0},
+		{"b-2", 3840, OggFlagEOS},
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("got %d pages, want %d", len(pages), len(want))
+	}
+	for i, p := range pages {
+		if i > 0 && string(p.Data) != want[i].data {
+			t.Errorf("page %d data %q, want %q", i, p.Data, want[i].data)
+		}
+		if p.Granule != want[i].granule || p.HeaderType != want[i].flags {
+			t.Errorf("page %d granule=%d flags=%d, want %d %d", i, p.Granule, p.HeaderType, want[i].granule, want[i].flags)
+		}
+		if p.Serial != 1 || p.Sequence != uint32(i) {
+			t.Errorf("page %d serial=%d sequence=%d", i, p.Serial, p.Sequence)
+		}
+	}
+}
+
+func TestRawJoiner(t *testing.T) {
+	var buf bytes.Buffer
+	j := NewAudioJoiner("pcm", &buf)
+	_ = j.Append([]byte{1, 2})
+	_ = j.Append([]byte{3})
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{1, 2, 3}) {
+		t.Fatalf("unexpected pcm: %v", buf.Bytes())
+	}
+}
+
+func TestPCMWavJoiner(t *testing.T) {
+	f := PCMWavFormat(24000, 16, 1)
+	if !f.Equal(testWavFormat()) {
+		t.Fatalf("PCMWavFormat = %s", f)
+	}
+	chunks := [][]byte{{1, 2}, {3, 4, 5, 6}}
+
+	// 不支持 Seek 的 writer 写入长度未知的头部，不缓存音频
+	var buf bytes.Buffer
+	j := NewPCMWavJoiner(f, &buf)
+	if err := j.Append(chunks[0]); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != wavHeaderSize+2 {
+		t.Fatalf("written = %d bytes before Close", buf.Len())
+	}
+	_ = j.Append(chunks[1])
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := append(WavHeader(f, wavUnknownSize), 1, 2, 3, 4, 5, 6)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("got %v, want %v", buf.Bytes(), want)
+	}
+	if format, data, err := ParseWav(buf.Bytes()); err != nil || !format.Equal(f) || len(data) != 6 {
+		t.Fatalf("ParseWav = %v, %v, %v", format, data, err)
+	}
+
+	// 文件支持 Seek，回写头部长度
+	file, err := os.CreateTemp(t.TempDir(), "pcm-*.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	j = NewPCMWavJoiner(f, file)
+	for _, c := range chunks {
+		if err := j.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testWav(f, []byte{1, 2, 3, 4, 5, 6}); !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// 没有分片时也输出有效的空文件
+	buf.Reset()
+	if err := NewPCMWavJoiner(f, &buf).Close(); err != nil || buf.Len() != wavHeaderSize {
+		t.Fatalf("empty = %d bytes, %v", buf.Len(), err)
+	}
+}
@@ -3,58 +3,26 @@ package internal
 import (
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 )
 
-func WriteToDisk(respBody io.Reader, outFile *os.File) error {
-	const bufferSize = 4096
-	buffer := make([]byte, bufferSize)
-	for {
-		bytesRead, err := respBody.Read(buffer)
-		if err == io.EOF {
-			break
-		}
-		if err != nil && err != io.EOF {
-			return err
-		}
-		if bytesRead == 0 {
-			break
-		}
-		if _, err := outFile.Write(buffer[:bytesRead]); err != nil {
-			return err
-		}
-	}
-	return nil
+// WriteToDisk 将 respBody 的内容全部写入 w
+func WriteToDisk(respBody io.Reader, w io.Writer) error {
+	_, err := io.Copy(w, respBody)
+	return err
 }
 
-func WriteBytesToDisk(b []byte, outFile *os.File) error {
-	_, err := outFile.Write(b)
+// WriteBytesToDisk 将 b 写入 w
+func WriteBytesToDisk(b []byte, w io.Writer) error {
+	_, err := w.Write(b)
 	return err
 }
 
 func WriteBytesToDiskFilename(b []byte, filename string) error {
 	outFile, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("os create file error: ", err)
+		return fmt.Errorf("os create file error: %w", err)
 	}
 	defer outFile.Close()
 	return WriteBytesToDisk(b, outFile)
 }
-
-func DownloadToDisk(url string, outFile *os.File) error {
-	// 从URL获取数据
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("error while getting the URL: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 将正文写入文件
-	_, err = io.Copy(outFile, resp.Body)
-	if err != nil {
-		return fmt.Errorf("error while writing to the file: %v", err)
-	}
-
-	return nil
-}
@@ -39,6 +39,9 @@ const timeout = 5 * time.Second
 type Option func(*HTTPClient)
 
 func NewHTTPClient(ctx context.Context, opts ...Option) *HTTPClient {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	h := &HTTPClient{
 		ctx: ctx,
 		client: &http.Client{
@@ -54,6 +57,15 @@ func NewHTTPClient(ctx context.Context, opts ...Option) *HTTPClient {
 	return h
 }
 
+// WithClient 使用外部的 http.Client
+// 会复制一份 client 以免修改调用方的配置，连接池仍由其 Transport 共享
+func WithClient(client *http.Client) Option {
+	return func(h *HTTPClient) {
+		c := *client
+		h.client = &c
+	}
+}
+
 func WithTimeout(timeout time.Duration) Option {
 	return func(h *HTTPClient) {
 		h.client.Timeout = timeout
@@ -97,7 +109,7 @@ func (hc *HTTPClient) SendRequest(method, url string, body map[string]any) (*htt
 		return nil, func() {}, errors.New("not define method: " + method)
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(hc.ctx, method, url, bytes.NewBuffer(reqBody))
 	hc.httpReq = req
 	if err != nil {
 		return nil, func() {}, err
@@ -184,7 +196,7 @@ func (hc *HTTPClient) setGet(targetUrl string, body map[string]any) string {
 }
 
 func (hc *HTTPClient) requestLogPrint() {
-	if !hc.requestLogSwitch {
+	if !hc.requestLogSwitch || hc.httpReq == nil || hc.httpRep == nil {
 		return
 	}
 
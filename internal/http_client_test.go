@@ -1,11 +1,72 @@
 package internal
 
-import "testing"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
 
 func TestHttpPost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != string(HttpJson) {
+			t.Errorf("Content-Type = %s", ct)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer;token" {
+			t.Errorf("Authorization = %s", auth)
+		}
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
 
+	client := NewHTTPClient(
+		context.TODO(),
+		WithHeader(map[string]any{"Authorization": "Bearer;token"}),
+		WithContentType(HttpJson),
+	)
+	resp, funcClose, err := client.SendRequest(http.MethodPost, srv.URL, map[string]any{"json": `{"a":1}`})
+	defer funcClose()
+	if err != nil {
+		t.Fatalf("SendRequest err = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"a":1}` {
+		t.Errorf("body = %s", body)
+	}
 }
 
 func TestHttpGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.RawQuery)
+	}))
+	defer srv.Close()
 
+	client := NewHTTPClient(context.TODO())
+	resp, funcClose, err := client.SendRequest(http.MethodGet, srv.URL, map[string]any{"appid": "a", "task_id": 1})
+	defer funcClose()
+	if err != nil {
+		t.Fatalf("SendRequest err = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	query, _ := url.ParseQuery(string(body))
+	if query.Get("appid") != "a" || query.Get("task_id") != "1" {
+		t.Errorf("query = %s", body)
+	}
+}
+
+func TestWithClient(t *testing.T) {
+	shared := &http.Client{}
+	client := NewHTTPClient(context.TODO(), WithClient(shared), WithTimeout(time.Second))
+	if client.client.Timeout != time.Second {
+		t.Errorf("timeout = %v", client.client.Timeout)
+	}
+	if shared.Timeout != 0 {
+		t.Errorf("shared client was modified, timeout = %v", shared.Timeout)
+	}
 }
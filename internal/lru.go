@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"container/list"
+	"time"
+)
+
+// LRU 按最近使用顺序淘汰的索引，支持条目数、总大小和过期时间限制
+// 不是并发安全的，由调用方加锁
+type LRU struct {
+	maxEntries int           // 最大条目数，为0时不限制
+	maxBytes   int64         // 最大总大小，为0时不限制
+	ttl        time.Duration // 过期时间，为0时不过期
+
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+
+	// OnEvict 条目被淘汰或过期时调用，主动 Remove 时不调用
+	OnEvict func(key string, value []byte)
+	// Now 当前时间，测试时可以替换
+	Now func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	size    int64
+	created time.Time
+}
+
+// NewLRU 创建索引，参数为0时不限制
+func NewLRU(maxEntries int, maxBytes int64, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		Now:        time.Now,
+	}
+}
+
+// Get 返回条目的值并标记为最近使用，过期的条目会被淘汰
+func (c *LRU) Get(key string) ([]byte, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if c.expired(entry) {
+		c.evict(e)
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return entry.value, true
+}
+
+// Add 添加或替换条目，size 为条目占用的大小，created 为创建时间，用于计算过期
+// 单个条目超过 maxBytes 时不添加，返回 false
+func (c *LRU) Add(key string, value []byte, size int64, created time.Time) bool {
+	if c.maxBytes > 0 && size > c.maxBytes {
+		c.Remove(key)
+		return false
+	}
+	if e, ok := c.items[key]; ok {
+		entry := e.Value.(*lruEntry)
+		c.bytes += size - entry.size
+		entry.value, entry.size, entry.created = value, size, created
+		c.ll.MoveToFront(e)
+	} else {
+		c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, size: size, created: created})
+		c.bytes += size
+	}
+	for c.overflow() {
+		c.evict(c.ll.Back())
+	}
+	return true
+}
+
+// Remove 删除条目，不调用 OnEvict
+func (c *LRU) Remove(key string) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Len 条目数
+func (c *LRU) Len() int {
+	return c.ll.Len()
+}
+
+// Bytes 条目的总大小
+func (c *LRU) Bytes() int64 {
+	return c.bytes
+}
+
+func (c *LRU) overflow() bool {
+	if c.ll.Len() == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.ll.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+func (c *LRU) expired(entry *lruEntry) bool {
+	return c.ttl > 0 && c.Now().Sub(entry.created) >= c.ttl
+}
+
+func (c *LRU) evict(e *list.Element) {
+	entry := c.remove(e)
+	if c.OnEvict != nil {
+		c.OnEvict(entry.key, entry.value)
+	}
+}
+
+func (c *LRU) remove(e *list.Element) *lruEntry {
+	entry := c.ll.Remove(e).(*lruEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+	return entry
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUMaxEntries(t *testing.T) {
+	c := NewLRU(2, 0, 0)
+	var evicted []string
+	c.OnEvict = func(key string, _ []byte) { evicted = append(evicted, key) }
+	now := time.Now()
+	c.Add("a", []byte("1"), 1, now)
+	c.Add("b", []byte("2"), 1, now)
+	c.Get("a")
+	c.Add("c", []byte("3"), 1, now)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should be evicted as least recently used")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a should be kept")
+	}
+	if len(evicted) != 1 || evicted[0] != "b" || c.Len() != 2 {
+		t.Errorf("evicted = %v, len = %d", evicted, c.Len())
+	}
+}
+
+func TestLRUMaxBytes(t *testing.T) {
+	c := NewLRU(0, 10, 0)
+	now := time.Now()
+	c.Add("a", nil, 4, now)
+	c.Add("b", nil, 4, now)
+	c.Add("a", nil, 6, now) // 替换后总大小为10
+	if c.Len() != 2 || c.Bytes() != 10 {
+		t.Fatalf("len = %d, bytes = %d", c.Len(), c.Bytes())
+	}
+	c.Add("c", nil, 3, now)
+	if _, ok := c.Get("b"); ok || c.Bytes() != 9 {
+		t.Errorf("b should be evicted, bytes = %d", c.Bytes())
+	}
+	if c.Add("d", nil, 11, now) {
+		t.Error("entry larger than maxBytes should not be added")
+	}
+}
+
+func TestLRUTTL(t *testing.T) {
+	c := NewLRU(0, 0, time.Minute)
+	now := time.Now()
+	c.Now = func() time.Time { return now }
+	var evictions int
+	c.OnEvict = func(string, []byte) { evictions++ }
+
+	c.Add("a", []byte("1"), 1, now.Add(-time.Second*30))
+	c.Add("b", []byte("2"), 1, now.Add(-time.Minute))
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a should not expire")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should expire")
+	}
+	if evictions != 1 || c.Len() != 1 {
+		t.Errorf("evictions = %d, len = %d", evictions, c.Len())
+	}
+
+	c.Remove("a")
+	if evictions != 1 || c.Len() != 0 || c.Bytes() != 0 {
+		t.Errorf("Remove should not call OnEvict, evictions = %d", evictions)
+	}
+}
@@ -0,0 +1,168 @@
+package internal
+
+import "errors"
+
+// Mp3Frame MPEG 音频帧头部信息
+type Mp3Frame struct {
+	Version    int // 1 为 MPEG1，2 为 MPEG2，25 为 MPEG2.5
+	Layer      int
+	Bitrate    int // kbps
+	SampleRate int
+	Padding    int
+	Channels   int
+	Size       int // 帧长度，包括头部
+	Samples    int // 每帧的采样数
+}
+
+var (
+	mp3BitratesV1L3 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2L3 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3SampleRates  = map[int][3]int{
+		1:  {44100, 48000, 32000},
+		2:  {22050, 24000, 16000},
+		25: {11025, 12000, 8000},
+	}
+)
+
+// ParseMp3Frame 解析 Layer III 帧头部
+func ParseMp3Frame(b []byte) (*Mp3Frame, error) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return nil, errors.New("invalid mp3 frame sync")
+	}
+
+	f := &Mp3Frame{}
+	switch (b[1] >> 3) & 0x03 {
+	case 0:
+		f.Version = 25
+	case 2:
+		f.Version = 2
+	case 3:
+		f.Version = 1
+	default:
+		return nil, errors.New("invalid mp3 version")
+	}
+	if (b[1]>>1)&0x03 != 1 {
+		return nil, errors.New("only mpeg layer III is supported")
+	}
+	f.Layer = 3
+
+	bitrateIdx := b[2] >> 4
+	rateIdx := (b[2] >> 2) & 0x03
+	if rateIdx == 3 {
+		return nil, errors.New("invalid mp3 sample rate")
+	}
+	if f.Version == 1 {
+		f.Bitrate = mp3BitratesV1L3[bitrateIdx]
+		f.Samples = 1152
+	} else {
+		f.Bitrate = mp3BitratesV2L3[bitrateIdx]
+		f.Samples = 576
+	}
+	if f.Bitrate == 0 {
+		return nil, errors.New("unsupported mp3 bitrate")
+	}
+	f.SampleRate = mp3SampleRates[f.Version][rateIdx]
+	f.Padding = int((b[2] >> 1) & 0x01)
+	f.Channels = 2
+	if b[3]>>6 == 3 {
+		f.Channels = 1
+	}
+
+	coefficient := 144
+	if f.Version != 1 {
+		coefficient = 72
+	}
+	f.Size = coefficient*f.Bitrate*1000/f.SampleRate + f.Padding
+	return f, nil
+}
+
+// sideInfoSize Layer III 帧头部之后的 side information 长度
+func (f *Mp3Frame) sideInfoSize() int {
+	if f.Version == 1 {
+		if f.Channels == 1 {
+			return 17
+		}
+		return 32
+	}
+	if f.Channels == 1 {
+		return 9
+	}
+	return 17
+}
+
+// Mp3VbrHeader 帧中的 Xing / Info / VBRI 信息
+type Mp3VbrHeader struct {
+	Tag    string // Xing、Info 或 VBRI
+	Frames int    // 总帧数，未知时为0
+	Bytes  int    // 总字节数，未知时为0
+}
+
+// ParseMp3VbrHeader 解析帧中的 Xing / Info / VBRI 信息，不存在时返回 nil
+func ParseMp3VbrHeader(frame []byte, f *Mp3Frame) *Mp3VbrHeader {
+	xing := 4 + f.sideInfoSize()
+	if len(frame) >= xing+8 {
+		tag := string(frame[xing : xing+4])
+		if tag == "Xing" || tag == "Info" {
+			h := &Mp3VbrHeader{Tag: tag}
+			flags := be32(frame[xing+4:])
+			pos := xing + 8
+			if flags&0x01 != 0 && len(frame) >= pos+4 {
+				h.Frames = int(be32(frame[pos:]))
+				pos += 4
+			}
+			if flags&0x02 != 0 && len(frame) >= pos+4 {
+				h.Bytes = int(be32(frame[pos:]))
+			}
+			return h
+		}
+	}
+
+	const vbri = 4 + 32
+	if len(frame) >= vbri+18 && string(frame[vbri:vbri+4]) == "VBRI" {
+		return &Mp3VbrHeader{
+			Tag:    "VBRI",
+			Bytes:  int(be32(frame[vbri+10:])),
+			Frames: int(be32(frame[vbri+14:])),
+		}
+	}
+	return nil
+}
+
+// Mp3ID3v2Size 文件开头 ID3v2 标签的长度，不存在时为0
+func Mp3ID3v2Size(b []byte) int {
+	if len(b) < 10 || string(b[0:3]) != "ID3" {
+		return 0
+	}
+	size := int(b[6]&0x7F)<<21 | int(b[7]&0x7F)<<14 | int(b[8]&0x7F)<<7 | int(b[9]&0x7F)
+	size += 10
+	// 存在 footer
+	if b[5]&0x10 != 0 {
+		size += 10
+	}
+	if size > len(b) {
+		return len(b)
+	}
+	return size
+}
+
+// StripMp3Tags 去掉 ID3v2、ID3v1 标签以及 Xing / Info / VBRI 信息帧，只保留音频帧
+// 拼接多段 mp3 时，这些信息只描述各自的分片，保留会导致播放器计算的时长错误
+func StripMp3Tags(b []byte) []byte {
+	b = b[Mp3ID3v2Size(b):]
+	if len(b) >= 128 && string(b[len(b)-128:len(b)-125]) == "TAG" {
+		b = b[:len(b)-128]
+	}
+
+	f, err := ParseMp3Frame(b)
+	if err != nil || f.Size > len(b) {
+		return b
+	}
+	if ParseMp3VbrHeader(b[:f.Size], f) != nil {
+		return b[f.Size:]
+	}
+	return b
+}
+
+func be32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	oggHeaderSize = 27
+
+	OggFlagContinued = 0x01 // 页面以上一页未结束的数据包开头
+	OggFlagBOS       = 0x02 // 逻辑流的第一页
+	OggFlagEOS       = 0x04 // 逻辑流的最后一页
+)
+
+// OggPage Ogg 页面
+type OggPage struct {
+	HeaderType uint8
+	Granule    int64
+	Serial     uint32
+	Sequence   uint32
+	Segments   []byte // 分段表
+	Data       []byte
+}
+
+// ParseOggPages 解析全部 Ogg 页面
+func ParseOggPages(b []byte) ([]*OggPage, error) {
+	var pages []*OggPage
+	for len(b) > 0 {
+		page, n, err := parseOggPage(b)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+		b = b[n:]
+	}
+	return pages, nil
+}
+
+func parseOggPage(b []byte) (*OggPage, int, error) {
+	if len(b) < oggHeaderSize || string(b[0:4]) != "OggS" {
+		return nil, 0, errors.New("invalid ogg page: missing capture pattern")
+	}
+	if b[4] != 0 {
+		return nil, 0, errors.New("invalid ogg page: unsupported version")
+	}
+	nSegs := int(b[26])
+	if len(b) < oggHeaderSize+nSegs {
+		return nil, 0, errors.New("invalid ogg page: truncated segment table")
+	}
+	segments := b[oggHeaderSize : oggHeaderSize+nSegs]
+	dataSize := 0
+	for _, s := range segments {
+		dataSize += int(s)
+	}
+	end := oggHeaderSize + nSegs + dataSize
+	if len(b) < end {
+		return nil, 0, errors.New("invalid ogg page: truncated data")
+	}
+	return &OggPage{
+		HeaderType: b[5],
+		Granule:    int64(binary.LittleEndian.Uint64(b[6:14])),
+		Serial:     binary.LittleEndian.Uint32(b[14:18]),
+		Sequence:   binary.LittleEndian.Uint32(b[18:22]),
+		Segments:   append([]byte(nil), segments...),
+		Data:       append([]byte(nil), b[oggHeaderSize+nSegs:end]...),
+	}, end, nil
+}
+
+// Bytes 序列化页面并重新计算校验和
+func (p *OggPage) Bytes() []byte {
+	b := make([]byte, oggHeaderSize+len(p.Segments)+len(p.Data))
+	copy(b[0:4], "OggS")
+	b[4] = 0
+	b[5] = p.HeaderType
+	binary.LittleEndian.PutUint64(b[6:14], uint64(p.Granule))
+	binary.LittleEndian.PutUint32(b[14:18], p.Serial)
+	binary.LittleEndian.PutUint32(b[18:22], p.Sequence)
+	b[26] = uint8(len(p.Segments))
+	copy(b[oggHeaderSize:], p.Segments)
+	copy(b[oggHeaderSize+len(p.Segments):], p.Data)
+	binary.LittleEndian.PutUint32(b[22:26], oggCRC(b))
+	return b
+}
+
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04C11DB7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggCRC Ogg 使用的 CRC32，多项式 0x04C11DB7，不反转，计算时校验和字段为0
+func oggCRC(b []byte) uint32 {
+	var crc uint32
+	for _, v := range b {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^v]
+	}
+	return crc
+}
+
+// OpusHead 解析 Opus 标识头中的声道数和 pre-skip
+func OpusHead(p *OggPage) (channels int, preSkip int, ok bool) {
+	if len(p.Data) < 19 || string(p.Data[0:8]) != "OpusHead" {
+		return 0, 0, false
+	}
+	return int(p.Data[9]), int(binary.LittleEndian.Uint16(p.Data[10:12])), true
+}
+
+// OpusInputRate Opus 标识头中记录的原始采样率，未记录时为0
+// Opus 解码总是使用 48kHz，granule 也按 48kHz 计数
+func OpusInputRate(p *OggPage) int {
+	if len(p.Data) < 19 || string(p.Data[0:8]) != "OpusHead" {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(p.Data[12:16]))
+}
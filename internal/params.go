@@ -2,8 +2,8 @@ package internal
 
 import "errors"
 
-// CheckParams 检查参数传递
-func CheckParams(params map[string]map[string]any) error {
+// CheckParams 检查参数传递，基本检查通过后依次执行 checks
+func CheckParams(params map[string]map[string]any, checks ...func(map[string]map[string]any) error) error {
 	if params["audio"] == nil {
 		params["audio"] = make(map[string]any)
 	}
@@ -17,6 +17,11 @@ func CheckParams(params map[string]map[string]any) error {
 	if !ok {
 		return errors.New("request.text cannot be empty")
 	}
+	for _, check := range checks {
+		if err := check(params); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
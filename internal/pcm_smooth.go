@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 计算能量的窗口长度，裁剪以窗口为单位
+const silenceWindow = 10 * time.Millisecond
+
+// SmoothOptions 分片边界的处理方式
+type SmoothOptions struct {
+	Threshold float64       // 静音的能量阈值，为相对满幅的 RMS（0~1）
+	Pause     time.Duration // 分片之间插入的静音
+	Crossfade time.Duration // 分片之间交叉淡化的时长，大于0时忽略 Pause
+}
+
+// ChunkSpan 分片在拼接音频中的位置
+type ChunkSpan struct {
+	Offset   time.Duration // 起始时间，交叉淡化时与上一个分片重叠
+	Duration time.Duration // 裁剪后的时长
+	Trimmed  time.Duration // 开头裁剪掉的静音时长
+}
+
+// SmoothJoiner 裁剪分片边界的静音，在分片之间插入固定的停顿或交叉淡化后交给 next 拼接
+// 第一个分片的开头和最后一个分片的结尾保持不变，只支持 16 位线性 PCM
+type SmoothJoiner struct {
+	next    AudioJoiner
+	wav     bool // 分片是完整的 wav 文件，输出时重新添加头部
+	format  *WavFormat
+	opts    SmoothOptions
+	pending []byte // 上一个分片裁剪开头后的数据，等到下一个分片时裁剪结尾
+	written int
+	spans   []byteSpan
+}
+
+type byteSpan struct {
+	offset, length, trimmed int
+}
+
+// NewSmoothJoiner 创建拼接器，format 为 pcm 分片的格式，为 nil 时分片为 wav 文件，格式从分片中读取
+func NewSmoothJoiner(format *WavFormat, opts SmoothOptions, next AudioJoiner) *SmoothJoiner {
+	return &SmoothJoiner{next: next, wav: format == nil, format: format, opts: opts}
+}
+
+func (j *SmoothJoiner) Append(chunk []byte) error {
+	data := chunk
+	if j.wav {
+		format, d, err := ParseWav(chunk)
+		if err != nil {
+			return err
+		}
+		if j.format == nil {
+			j.format = format
+		} else if !j.format.Equal(format) {
+			return fmt.Errorf("wav chunk format mismatch: %s != %s", format, j.format)
+		}
+		data = d
+	}
+	if j.format.AudioFormat != 1 || j.format.BitsPerSample != 16 || j.format.BlockAlign == 0 {
+		return fmt.Errorf("smooth join: unsupported pcm format %s", j.format)
+	}
+	data = data[:len(data)-len(data)%int(j.format.BlockAlign)]
+
+	if len(j.spans) == 0 {
+		j.spans = append(j.spans, byteSpan{length: len(data)})
+		j.pending = data
+		return nil
+	}
+
+	lead := j.leadingSilence(data)
+	data = data[lead:]
+	prev := j.pending[:j.trailingEnd(j.pending)]
+	j.spans[len(j.spans)-1].length = len(prev)
+
+	if n := j.bytes(j.opts.Crossfade); n > 0 {
+		if n > len(prev) {
+			n = len(prev)
+		}
+		if n > len(data) {
+			n = len(data)
+		}
+		if err := j.emit(prev[:len(prev)-n]); err != nil {
+			return err
+		}
+		mixed := crossfade(prev[len(prev)-n:], data[:n], int(j.format.Channels))
+		data = append(mixed, data[n:]...)
+	} else {
+		if err := j.emit(prev); err != nil {
+			return err
+		}
+		if err := j.emit(make([]byte, j.bytes(j.opts.Pause))); err != nil {
+			return err
+		}
+	}
+
+	j.spans = append(j.spans, byteSpan{offset: j.written, length: len(data), trimmed: lead})
+	j.pending = data
+	return nil
+}
+
+func (j *SmoothJoiner) Close() error {
+	if err := j.emit(j.pending); err != nil {
+		return err
+	}
+	j.pending = nil
+	return j.next.Close()
+}
+
+// Spans 每个分片在拼接音频中的位置，Close 之后调用
+func (j *SmoothJoiner) Spans() []ChunkSpan {
+	spans := make([]ChunkSpan, 0, len(j.spans))
+	for _, s := range j.spans {
+		spans = append(spans, ChunkSpan{
+			Offset:   j.duration(s.offset),
+			Duration: j.duration(s.length),
+			Trimmed:  j.duration(s.trimmed),
+		})
+	}
+	return spans
+}
+
+// emit 输出 pcm，wav 分片重新添加头部
+func (j *SmoothJoiner) emit(pcm []byte) error {
+	if len(pcm) == 0 {
+		return nil
+	}
+	j.written += len(pcm)
+	if j.wav {
+		return j.next.Append(append(WavHeader(j.format, uint32(len(pcm))), pcm...))
+	}
+	return j.next.Append(pcm)
+}
+
+// bytes 时长对应的字节数，按采样帧对齐
+func (j *SmoothJoiner) bytes(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	align := int64(j.format.BlockAlign)
+	n := int64(d) * int64(j.format.SampleRate) / int64(time.Second)
+	return int(n * align)
+}
+
+func (j *SmoothJoiner) duration(n int) time.Duration {
+	return time.Duration(int64(n) * int64(time.Second) / int64(j.format.SampleRate) / int64(j.format.BlockAlign))
+}
+
+// leadingSilence 开头静音的字节数，全部为静音时返回 len(b)
+func (j *SmoothJoiner) leadingSilence(b []byte) int {
+	window := j.window()
+	for start := 0; start < len(b); start += window {
+		end := start + window
+		if end > len(b) {
+			end = len(b)
+		}
+		if rms(b[start:end]) > j.opts.Threshold {
+			return start
+		}
+	}
+	return len(b)
+}
+
+// trailingEnd 去掉结尾静音后的长度
+func (j *SmoothJoiner) trailingEnd(b []byte) int {
+	window := j.window()
+	for end := len(b); end > 0; end -= window {
+		start := end - window
+		if start < 0 {
+			start = 0
+		}
+		if rms(b[start:end]) > j.opts.Threshold {
+			return end
+		}
+	}
+	return 0
+}
+
+func (j *SmoothJoiner) window() int {
+	if n := j.bytes(silenceWindow); n > 0 {
+		return n
+	}
+	return int(j.format.BlockAlign)
+}
+
+// rms 16 位采样相对满幅的均方根
+func rms(b []byte) float64 {
+	n := len(b) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		v := float64(int16(binary.LittleEndian.Uint16(b[2*i:])))
+		sum += v * v
+	}
+	return math.Sqrt(sum/float64(n)) / 32768
+}
+
+// crossfade 线性交叉淡化两段长度相同的 16 位 pcm，a 淡出 b 淡入
+func crossfade(a, b []byte, channels int) []byte {
+	out := make([]byte, len(a))
+	samples := len(a) / 2
+	frames := samples / channels
+	for i := 0; i < samples; i++ {
+		t := (float64(i/channels) + 0.5) / float64(frames)
+		va := float64(int16(binary.LittleEndian.Uint16(a[2*i:])))
+		vb := float64(int16(binary.LittleEndian.Uint16(b[2*i:])))
+		v := math.Round(va*(1-t) + vb*t)
+		if v > math.MaxInt16 {
+			v = math.MaxInt16
+		} else if v < math.MinInt16 {
+			v = math.MinInt16
+		}
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(v)))
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// testPCM 按顺序生成 1kHz 16 位单声道 pcm，正数为振幅固定的方波采样数，负数为静音采样数
+func testPCM(parts ...int) []byte {
+	var b []byte
+	for _, n := range parts {
+		v := int16(0)
+		if n > 0 {
+			v = 10000
+		} else {
+			n = -n
+		}
+		for i := 0; i < n; i++ {
+			s := v
+			if i%2 == 1 {
+				s = -v
+			}
+			b = appendUint16(b, uint16(s))
+		}
+	}
+	return b
+}
+
+func smoothJoin(t *testing.T, j *SmoothJoiner, chunks ...[]byte) {
+	t.Helper()
+	for _, c := range chunks {
+		if err := j.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSmoothJoinerPause(t *testing.T) {
+	f := PCMWavFormat(1000, 16, 1)
+	chunks := [][]byte{testPCM(50, -30), testPCM(-20, 40, -20), testPCM(-20, 10, -5)}
+
+	var buf bytes.Buffer
+	j := NewSmoothJoiner(f, SmoothOptions{Threshold: 0.01, Pause: 10 * time.Millisecond}, NewAudioJoiner("pcm", &buf))
+	smoothJoin(t, j, chunks...)
+
+	// 分片之间的静音统一为 10ms，最后一个分片的结尾保持不变
+	if want := testPCM(50, -10, 40, -10, 10, -5); !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("got %d bytes, want %d", buf.Len(), len(want))
+	}
+	ms := time.Millisecond
+	want := []ChunkSpan{{0, 50 * ms, 0}, {60 * ms, 40 * ms, 20 * ms}, {110 * ms, 15 * ms, 20 * ms}}
+	spans := j.Spans()
+	if len(spans) != len(want) {
+		t.Fatalf("spans = %+v", spans)
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Errorf("span %d = %+v, want %+v", i, spans[i], want[i])
+		}
+	}
+
+	// wav 分片从头部读取格式，输出合并为一个头部
+	buf.Reset()
+	j = NewSmoothJoiner(nil, SmoothOptions{Threshold: 0.01, Pause: 10 * time.Millisecond}, NewAudioJoiner("wav", &buf))
+	smoothJoin(t, j, testWav(f, chunks[0]), testWav(f, chunks[1]), testWav(f, chunks[2]))
+	if want := testWav(f, testPCM(50, -10, 40, -10, 10, -5)); !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("wav got %d bytes, want %d", buf.Len(), len(want))
+	}
+}
+
+func TestSmoothJoinerCrossfade(t *testing.T) {
+	f := PCMWavFormat(1000, 16, 1)
+	var buf bytes.Buffer
+	j := NewSmoothJoiner(f, SmoothOptions{Threshold: 0.01, Crossfade: 10 * time.Millisecond}, NewAudioJoiner("pcm", &buf))
+	smoothJoin(t, j, testPCM(-5, 40, -20), testPCM(-30, 40))
+
+	// 重叠 10 个采样：5 + 40 + 40 - 10
+	if n := buf.Len() / 2; n != 75 {
+		t.Fatalf("samples = %d, want 75", n)
+	}
+	// 重叠部分的振幅不超过两段中较大的振幅
+	for i := 35; i < 45; i++ {
+		v := int16(binary.LittleEndian.Uint16(buf.Bytes()[2*i:]))
+		if v > 10000 || v < -10000 {
+			t.Errorf("sample %d = %d", i, v)
+		}
+	}
+	spans := j.Spans()
+	if len(spans) != 2 || spans[1].Offset != 35*time.Millisecond || spans[1].Trimmed != 30*time.Millisecond || spans[0].Duration != 45*time.Millisecond {
+		t.Errorf("spans = %+v", spans)
+	}
+
+	// 不支持 16 位以外的格式
+	j = NewSmoothJoiner(PCMWavFormat(1000, 8, 1), SmoothOptions{}, NewAudioJoiner("pcm", &buf))
+	if err := j.Append([]byte{1, 2}); err == nil {
+		t.Error("expected error for 8 bit pcm")
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限流器，可以在多个协程之间共享
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒生成的令牌数
+	burst  float64 // 令牌桶容量
+	tokens float64 // 当前令牌数，预约等待时可能为负数
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimiter 创建限流器，qps 为每秒允许的请求数，burst 为允许的突发请求数
+func NewRateLimiter(qps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &RateLimiter{
+		rate:   qps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+	l.last = l.now()
+	return l
+}
+
+// advance 按经过的时间补充令牌，调用方需要持有锁
+func (l *RateLimiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed > 0 {
+		l.tokens += elapsed * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+}
+
+// Allow 立即获取一个令牌，没有可用令牌时返回 false
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(l.now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait 阻塞直到获取一个令牌
+// ctx 结束或者等待时间超过 ctx 的截止时间时返回错误，并归还预约的令牌
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	l.advance(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < wait {
+		l.tokens++
+		l.mu.Unlock()
+		return fmt.Errorf("rate limiter wait %v would exceed context deadline: %w", wait, context.DeadlineExceeded)
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
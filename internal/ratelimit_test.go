@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(10, 2)
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	if !l.Allow() || !l.Allow() {
+		t.Fatal("burst tokens should be allowed")
+	}
+	if l.Allow() {
+		t.Fatal("bucket should be empty")
+	}
+
+	now = now.Add(time.Millisecond * 100)
+	if !l.Allow() {
+		t.Fatal("token should be refilled after 100ms")
+	}
+	if l.Allow() {
+		t.Fatal("only one token should be refilled")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(20, 1)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("Wait err = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 第1个请求立即通过，其余4个请求间隔50ms
+	if elapsed := time.Since(start); elapsed < time.Millisecond*180 {
+		t.Errorf("5 waits at 20 QPS took %v, want >= 200ms", elapsed)
+	}
+}
+
+func TestRateLimiterWaitDeadline(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait err = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	start := time.Now()
+	err := l.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > time.Millisecond*50 {
+		t.Errorf("Wait should fail fast when deadline is too close")
+	}
+
+	// 失败的等待会归还令牌，下一次等待不会被多扣
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.01 || tokens > 0.2 {
+		t.Errorf("tokens = %v after failed wait", tokens)
+	}
+}
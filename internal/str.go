@@ -1,6 +1,10 @@
 package internal
 
-import "unicode/utf8"
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 // SplitText 将文本分割成每个元素最大maxBytes字节的切片
 func SplitText(text string, maxBytes int) []string {
@@ -32,3 +36,99 @@ func SplitText(text string, maxBytes int) []string {
 	}
 	return result
 }
+
+// SplitSentences 将文本分割成每个元素最大maxBytes字节的切片，尽量在自然停顿处切分
+// 切分位置的优先级：换行、句末标点、分句标点、空白，都找不到时退回到字符边界
+// 只包含空白的片段会被丢弃
+func SplitSentences(text string, maxBytes int) []string {
+	var result []string
+	for len(text) > maxBytes {
+		cut := sentenceCut(text, maxBytes)
+		result = appendChunk(result, text[:cut])
+		text = text[cut:]
+	}
+	return appendChunk(result, text)
+}
+
+func appendChunk(result []string, s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return result
+	}
+	return append(result, s)
+}
+
+// sentenceCut 在 text 的前 maxBytes 字节内找到最合适的切分位置，要求 len(text) > maxBytes
+func sentenceCut(text string, maxBytes int) int {
+	limit := maxBytes
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	// maxBytes 小于单个字符的长度时，至少保留一个字符
+	if limit == 0 {
+		_, size := utf8.DecodeRuneInString(text)
+		return size
+	}
+
+	var paragraph, sentence, clause, space int
+	for i := 0; i < limit; {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		end := i + size
+		switch {
+		case r == '\n':
+			paragraph = end
+		case isSentenceEnd(text, r, end):
+			sentence = skipClosing(text, end, limit)
+		case isClauseEnd(text, r, end):
+			clause = end
+		case unicode.IsSpace(r):
+			space = end
+		}
+		i = end
+	}
+
+	for _, cut := range []int{paragraph, sentence, clause, space} {
+		if cut > 0 {
+			return cut
+		}
+	}
+	return limit
+}
+
+// isSentenceEnd 句末标点，英文句号后面必须是空白或文本结尾，避免切开小数、缩写和网址
+func isSentenceEnd(text string, r rune, end int) bool {
+	switch r {
+	case '。', '！', '？', '…', '!', '?':
+		return true
+	case '.':
+		return end == len(text) || nextIsSpace(text, end)
+	}
+	return false
+}
+
+// isClauseEnd 分句标点，英文逗号、分号后面是数字时不切分，例如 1,000
+func isClauseEnd(text string, r rune, end int) bool {
+	switch r {
+	case '，', '；', '、':
+		return true
+	case ',', ';':
+		return end == len(text) || text[end] < '0' || text[end] > '9'
+	}
+	return false
+}
+
+func nextIsSpace(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsSpace(r)
+}
+
+// skipClosing 句末标点后面紧跟的引号、括号和重复的标点属于同一句
+func skipClosing(text string, end, limit int) int {
+	for end < limit {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if end+size > limit || !strings.ContainsRune("”’」』）)》】\"'。！？!?…", r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 )
 
@@ -19,3 +20,50 @@ func TestSplitText(t *testing.T) {
 	}
 	fmt.Printf("%s \n\n", jsonStr)
 }
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxBytes int
+		want     []string
+	}{
+		{"short", "月光如水洒落。", 1024, []string{"月光如水洒落。"}},
+		{"empty", "", 1024, nil},
+		{"sentence", "昏黑的夜色中。月光如水洒落，静谧而神秘。", 40, []string{"昏黑的夜色中。", "月光如水洒落，静谧而神秘。"}},
+		{"clause", "月光如水洒落，静谧而神秘仿佛", 30, []string{"月光如水洒落，", "静谧而神秘仿佛"}},
+		{"paragraph", "第一段。\n第二段。第三句。", 28, []string{"第一段。\n", "第二段。第三句。"}},
+		{"closing quote", "他说：“你好。”然后离开", 24, []string{"他说：“你好。”", "然后离开"}},
+		{"latin sentence", "Hello world. How are you", 20, []string{"Hello world. ", "How are you"}},
+		{"latin decimal", "pi is 3.14159 ok", 12, []string{"pi is ", "3.14159 ok"}},
+		{"latin comma", "1,000 apples, 2 pears", 16, []string{"1,000 apples, ", "2 pears"}},
+		{"no boundary", "月光如水洒落", 10, []string{"月光如", "水洒落"}},
+		{"tiny limit", "月光", 1, []string{"月", "光"}},
+		{"trailing space", "你好。      ", 10, []string{"你好。"}},
+	}
+	for _, tt := range tests {
+		got := SplitSentences(tt.text, tt.maxBytes)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || len(got) != len(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitSentencesLimit(t *testing.T) {
+	text := strings.Repeat("昏黑的夜色中，月光如水洒落，静谧而神秘，仿佛将世界染上一抹深邃的诗意。Moonlight falls like water. ", 50)
+	chunks := SplitSentences(text, 1024)
+	if len(chunks) < 2 {
+		t.Fatalf("chunks = %d, want more than 1", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c) > 1024 {
+			t.Errorf("chunk %d has %d bytes", i, len(c))
+		}
+		if i < len(chunks)-1 && !strings.HasSuffix(strings.TrimSpace(c), ".") && !strings.HasSuffix(c, "。") {
+			t.Errorf("chunk %d does not end at a sentence: %q", i, c[len(c)-10:])
+		}
+	}
+	if strings.Join(chunks, "") != text {
+		t.Error("joined chunks differ from input")
+	}
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// 标准 WAV 头部长度：RIFF(12) + fmt(8+16) + data(8)
+	wavHeaderSize = 44
+	// 流式写入时长度未知的块使用的长度，也是块长度的最大值
+	wavUnknownSize = 0xFFFFFFFF
+)
+
+// WavFormat WAV 文件的 fmt 块
+type WavFormat struct {
+	AudioFormat   uint16 // 1 为 PCM
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+	Extra         []byte // fmt 块中 16 字节之后的扩展内容
+}
+
+// ParseWav 解析 WAV 文件，返回 fmt 块和 data 块的内容
+// 流式合成的 data 块长度可能为 0 或 0xFFFFFFFF，此时取到文件末尾
+func ParseWav(b []byte) (*WavFormat, []byte, error) {
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, nil, errors.New("invalid wav: missing RIFF/WAVE header")
+	}
+
+	var format *WavFormat
+	pos := 12
+	for pos+8 <= len(b) {
+		id := string(b[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(b[pos+4 : pos+8]))
+		body := pos + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || body+size > len(b) {
+				return nil, nil, errors.New("invalid wav: bad fmt chunk")
+			}
+			f := b[body : body+size]
+			format = &WavFormat{
+				AudioFormat:   binary.LittleEndian.Uint16(f[0:2]),
+				Channels:      binary.LittleEndian.Uint16(f[2:4]),
+				SampleRate:    binary.LittleEndian.Uint32(f[4:8]),
+				ByteRate:      binary.LittleEndian.Uint32(f[8:12]),
+				BlockAlign:    binary.LittleEndian.Uint16(f[12:14]),
+				BitsPerSample: binary.LittleEndian.Uint16(f[14:16]),
+			}
+			if size > 16 {
+				format.Extra = append([]byte(nil), f[16:]...)
+			}
+		case "data":
+			if format == nil {
+				return nil, nil, errors.New("invalid wav: data chunk before fmt chunk")
+			}
+			end := body + size
+			if size == 0 || end > len(b) || end < body {
+				end = len(b)
+			}
+			return format, b[body:end], nil
+		}
+
+		// 块长度为奇数时有1字节填充
+		pos = body + size + size%2
+	}
+	return nil, nil, errors.New("invalid wav: missing data chunk")
+}
+
+// Equal 两个 fmt 块是否一致
+func (f *WavFormat) Equal(o *WavFormat) bool {
+	return f.AudioFormat == o.AudioFormat &&
+		f.Channels == o.Channels &&
+		f.SampleRate == o.SampleRate &&
+		f.BitsPerSample == o.BitsPerSample
+}
+
+// String 用于错误信息
+func (f *WavFormat) String() string {
+	return fmt.Sprintf("format=%d channels=%d rate=%d bits=%d", f.AudioFormat, f.Channels, f.SampleRate, f.BitsPerSample)
+}
+
+// PCMWavFormat 线性 PCM 的 fmt 块
+func PCMWavFormat(sampleRate, bitsPerSample, channels int) *WavFormat {
+	blockAlign := channels * bitsPerSample / 8
+	return &WavFormat{
+		AudioFormat:   1,
+		Channels:      uint16(channels),
+		SampleRate:    uint32(sampleRate),
+		ByteRate:      uint32(sampleRate * blockAlign),
+		BlockAlign:    uint16(blockAlign),
+		BitsPerSample: uint16(bitsPerSample),
+	}
+}
+
+// WavHeader 生成 WAV 头部，dataSize 为 data 块长度
+func WavHeader(f *WavFormat, dataSize uint32) []byte {
+	fmtSize := 16 + len(f.Extra)
+	header := make([]byte, 0, wavHeaderSize+len(f.Extra))
+	header = append(header, "RIFF"...)
+	header = appendUint32(header, wavRiffSize(fmtSize, dataSize))
+	header = append(header, "WAVE"...)
+	header = append(header, "fmt "...)
+	header = appendUint32(header, uint32(fmtSize))
+	header = appendUint16(header, f.AudioFormat)
+	header = appendUint16(header, f.Channels)
+	header = appendUint32(header, f.SampleRate)
+	header = appendUint32(header, f.ByteRate)
+	header = appendUint16(header, f.BlockAlign)
+	header = appendUint16(header, f.BitsPerSample)
+	header = append(header, f.Extra...)
+	header = append(header, "data"...)
+	header = appendUint32(header, dataSize)
+	return header
+}
+
+// wavRiffSize RIFF 块长度，超出 uint32 时取最大值
+func wavRiffSize(fmtSize int, dataSize uint32) uint32 {
+	size := uint64(4+8+fmtSize+8) + uint64(dataSize)
+	if size > wavUnknownSize {
+		return wavUnknownSize
+	}
+	return uint32(size)
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
@@ -0,0 +1,314 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WebSocket 操作码
+const (
+	WsContinuation = 0x0
+	WsText         = 0x1
+	WsBinary       = 0x2
+	WsClose        = 0x8
+	WsPing         = 0x9
+	WsPong         = 0xA
+)
+
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// 单个消息的最大长度，防止异常数据占用过多内存
+	wsMaxMessageSize = 64 << 20
+)
+
+// WsHandshakeError 握手失败，服务端没有返回 101
+type WsHandshakeError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *WsHandshakeError) Error() string {
+	return fmt.Sprintf("websocket handshake failed: status=%d body=%s", e.StatusCode, e.Body)
+}
+
+// WsConn 最小的 WebSocket 连接，支持收发完整消息和 ping / pong / close 控制帧
+// 只支持一个协程读、多个协程写
+type WsConn struct {
+	rwc    io.ReadWriteCloser
+	br     *bufio.Reader
+	client bool // 客户端发送的帧需要掩码
+
+	wmu       sync.Mutex
+	closeSent bool // 已经发送 close 帧，之后不能再发送任何帧
+	closeOnce sync.Once
+}
+
+// DialWebSocket 使用 client 发起 WebSocket 握手，rawURL 支持 ws / wss / http / https
+// 握手之后的连接不受 client.Timeout 限制，由调用方通过 Close 结束
+func DialWebSocket(ctx context.Context, client *http.Client, rawURL string, header http.Header) (*WsConn, error) {
+	switch {
+	case strings.HasPrefix(rawURL, "ws://"):
+		rawURL = "http://" + strings.TrimPrefix(rawURL, "ws://")
+	case strings.HasPrefix(rawURL, "wss://"):
+		rawURL = "https://" + strings.TrimPrefix(rawURL, "wss://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http new request error: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	c := *client
+	c.Timeout = 0
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, &WsHandshakeError{StatusCode: resp.StatusCode, Body: body}
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("websocket handshake failed: response body is not writable")
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") || resp.Header.Get("Sec-WebSocket-Accept") != wsAcceptKey(key) {
+		rwc.Close()
+		return nil, errors.New("websocket handshake failed: invalid upgrade response")
+	}
+	return &WsConn{rwc: rwc, br: bufio.NewReader(rwc), client: true}, nil
+}
+
+// AcceptWebSocket 服务端完成 WebSocket 握手，用于测试服务
+func AcceptWebSocket(w http.ResponseWriter, r *http.Request) (*WsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket upgrade required")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijack not supported", http.StatusInternalServerError)
+		return nil, errors.New("http.ResponseWriter does not support hijack")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAcceptKey(key) + "\r\n\r\n"
+	if _, err := brw.WriteString(resp); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WsConn{rwc: conn, br: brw.Reader}, nil
+}
+
+func wsAcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// WriteMessage 发送一个完整的消息
+func (c *WsConn) WriteMessage(opcode byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return errors.New("websocket: connection closed")
+	}
+	return c.writeFrame(opcode, payload)
+}
+
+func (c *WsConn) writeFrame(opcode byte, payload []byte) error {
+	return c.writeFrameFin(true, opcode, payload)
+}
+
+// writeFrameFin 发送一个帧，fin 为 false 时后续还有分片帧
+func (c *WsConn) writeFrameFin(fin bool, opcode byte, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	if fin {
+		opcode |= 0x80
+	}
+	frame = append(frame, opcode)
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126, byte(n>>8), byte(n))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(n))
+		frame = append(frame, maskBit|127)
+		frame = append(frame, ext[:]...)
+	}
+
+	if !c.client {
+		frame = append(frame, payload...)
+	} else {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		for i := range frame[start:] {
+			frame[start+i] ^= mask[i%4]
+		}
+	}
+
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+// ReadMessage 读取一个完整的数据消息，自动合并分片帧并回复 ping
+// 收到 close 帧后回复 close 并返回 io.EOF
+func (c *WsConn) ReadMessage() (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case WsPing:
+			c.wmu.Lock()
+			err := c.writeFrame(WsPong, payload)
+			c.wmu.Unlock()
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case WsPong:
+			continue
+		case WsClose:
+			c.sendClose(payload)
+			return 0, nil, io.EOF
+		case WsContinuation:
+			if message == nil {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		default:
+			if message != nil {
+				return 0, nil, errors.New("websocket: expected continuation frame")
+			}
+			opcode = op
+			message = []byte{}
+		}
+
+		if len(message)+len(payload) > wsMaxMessageSize {
+			return 0, nil, errors.New("websocket: message too large")
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *WsConn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+
+	size := uint64(head[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > wsMaxMessageSize {
+		return false, 0, nil, errors.New("websocket: frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// Close 发送 close 帧并关闭连接，可以重复调用
+func (c *WsConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		// 1000 正常关闭
+		c.sendClose([]byte{0x03, 0xE8})
+		err = c.rwc.Close()
+	})
+	return err
+}
+
+func (c *WsConn) sendClose(payload []byte) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return
+	}
+	c.closeSent = true
+	_ = c.writeFrame(WsClose, payload)
+}
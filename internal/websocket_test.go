@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebSocketEcho(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := AcceptWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// 先发送 ping，客户端读取时自动回复
+		_ = conn.WriteMessage(WsPing, []byte("ping"))
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			// 分两个帧回复，验证客户端合并分片
+			half := len(msg) / 2
+			conn.wmu.Lock()
+			_ = conn.writeFrameFin(false, op, msg[:half])
+			_ = conn.writeFrameFin(true, WsContinuation, msg[half:])
+			conn.wmu.Unlock()
+		}
+	}))
+	defer srv.Close()
+
+	conn, err := DialWebSocket(context.Background(), http.DefaultClient, "ws"+srv.URL[len("http"):], nil)
+	if err != nil {
+		t.Fatalf("DialWebSocket err = %v", err)
+	}
+	defer conn.Close()
+
+	for _, size := range []int{0, 10, 200, 70000} {
+		payload := bytes.Repeat([]byte{'a'}, size)
+		if err := conn.WriteMessage(WsBinary, payload); err != nil {
+			t.Fatalf("WriteMessage err = %v", err)
+		}
+		op, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage err = %v", err)
+		}
+		if op != WsBinary || !bytes.Equal(msg, payload) {
+			t.Errorf("size %d: got opcode %d length %d", size, op, len(msg))
+		}
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close err = %v", err)
+	}
+	if err := conn.WriteMessage(WsBinary, nil); err == nil {
+		t.Error("expected error after Close")
+	}
+}
+
+func TestWebSocketServerClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := AcceptWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	conn, err := DialWebSocket(context.Background(), http.DefaultClient, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("DialWebSocket err = %v", err)
+	}
+	defer conn.Close()
+	if _, _, err := conn.ReadMessage(); err != io.EOF {
+		t.Errorf("ReadMessage err = %v, want io.EOF", err)
+	}
+}
+
+func TestWebSocketHandshakeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := DialWebSocket(context.Background(), http.DefaultClient, srv.URL, nil)
+	var hsErr *WsHandshakeError
+	if !errors.As(err, &hsErr) || hsErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("err = %v, want 403 handshake error", err)
+	}
+}
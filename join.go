@@ -0,0 +1,90 @@
+package go_byte_tts
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/audioinfo"
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// JoinResult 超长文本分片合成并拼接的结果
+type JoinResult struct {
+	Chunks   []ChunkResult // 按顺序的每个分片
+	Duration time.Duration // 拼接后的总时长
+	Timeline *Timeline     // 按分片起始时间偏移后的时间戳，请求未设置 with_frontend 时为 nil
+}
+
+// ChunkResult 单个分片的合成结果
+type ChunkResult struct {
+	Index    int
+	Text     string          // 分片的文本
+	Offset   time.Duration   // 分片在拼接音频中的起始时间
+	Duration time.Duration   // 分片的时长，开启 [WithJoinSmoothing] 时为裁剪后的时长
+	Info     *audioinfo.Info // 分片音频的信息，无法解析时为 nil
+	ReqID    string
+	Cached   bool
+
+	timeline *Timeline // 分片自身的时间戳，拼接完成后偏移到 JoinResult.Timeline
+}
+
+// add 追加下一个分片，拼接完成后由 place 计算起始时间
+func (r *JoinResult) add(text string, res *SynthesisResult) {
+	r.Chunks = append(r.Chunks, ChunkResult{
+		Index:    len(r.Chunks),
+		Text:     text,
+		Duration: chunkDuration(res),
+		Info:     res.Info,
+		ReqID:    res.ReqID,
+		Cached:   res.Cached,
+		timeline: res.Timeline,
+	})
+}
+
+// place 计算每个分片的起始时间并合并时间戳
+// spans 为处理分片边界后的位置，为 nil 时分片首尾相连，起始时间为之前分片的时长之和
+func (r *JoinResult) place(spans []internal.ChunkSpan) {
+	var end time.Duration
+	for i := range r.Chunks {
+		c := &r.Chunks[i]
+		c.Offset = end
+		shift := end
+		if i < len(spans) {
+			c.Offset = spans[i].Offset
+			c.Duration = spans[i].Duration
+			shift = c.Offset - spans[i].Trimmed
+		}
+		end = c.Offset + c.Duration
+		if c.timeline != nil {
+			if r.Timeline == nil {
+				r.Timeline = &Timeline{}
+			}
+			r.Timeline.appendShifted(c.timeline, shift)
+		}
+	}
+	r.Duration = end
+}
+
+// chunkDuration 分片的音频时长，依次使用服务端返回的 addition.duration、解析音频得到的时长和最后一个时间戳的结束时间
+// 最后一个时间戳之后还有结尾的静音，只在前两者都没有时使用
+func chunkDuration(result *SynthesisResult) time.Duration {
+	switch {
+	case result.Duration > 0:
+		return result.Duration
+	case result.Info != nil && result.Info.Duration > 0:
+		return result.Info.Duration
+	case result.Timeline != nil:
+		return result.Timeline.End()
+	}
+	return 0
+}
+
+// TextToJoinVoiceResult 与 [GoTTS.TextToJoinVoiceWriter] 相同，同时返回每个分片的结果
+func (g *GoTTS) TextToJoinVoiceResult(ctx context.Context, params map[string]map[string]any, w io.Writer) (*JoinResult, error) {
+	result := &JoinResult{}
+	if err := g.joinVoice(ctx, params, w, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
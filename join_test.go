@@ -0,0 +1,73 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/audioinfo"
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func TestSynthesizeInfo(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(bytettstest.Response{Audio: make([]byte, 16000)})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Audio.Encoding = "pcm"
+	req.Audio.Rate = 8000
+	result, err := tts.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 服务端未返回时长，由 pcm 的长度和 audio.rate 计算
+	if result.Duration != 0 || result.Info == nil || result.Info.Duration != time.Second || result.Info.SampleRate != 8000 {
+		t.Errorf("Duration = %v, Info = %+v", result.Duration, result.Info)
+	}
+}
+
+func TestTextToJoinVoiceResult(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	first := strings.Repeat("一", 300) + "。"
+	second := strings.Repeat("二", 100)
+	params := map[string]map[string]any{
+		"audio":   {"voice_type": "BV406_V2_streaming", "encoding": "pcm"},
+		"request": {"reqid": "reqid", "text": first + second, "operation": "query"},
+	}
+	var buf bytes.Buffer
+	result, err := tts.TextToJoinVoiceResult(context.Background(), params, &buf)
+	if err != nil {
+		t.Fatalf("TextToJoinVoiceResult err = %v", err)
+	}
+	if len(result.Chunks) != 2 {
+		t.Fatalf("chunks = %d, want 2", len(result.Chunks))
+	}
+
+	// 模拟服务返回请求文本作为 pcm，按默认 24kHz 16 位单声道计算时长
+	durationOf := func(s string) time.Duration {
+		info, _ := audioinfo.ProbePCM(len(s), audioinfo.PCMFormat{})
+		return info.Duration
+	}
+	for i, text := range []string{first, second} {
+		c := result.Chunks[i]
+		if c.Index != i || c.Text != text || c.ReqID == "" || c.Info == nil || c.Duration != durationOf(text) {
+			t.Errorf("chunk %d = %+v", i, c)
+		}
+	}
+	if result.Chunks[0].Offset != 0 || result.Chunks[1].Offset != result.Chunks[0].Duration {
+		t.Errorf("offsets = %v, %v", result.Chunks[0].Offset, result.Chunks[1].Offset)
+	}
+	if result.Duration != durationOf(first)+durationOf(second) || result.Timeline != nil {
+		t.Errorf("Duration = %v, Timeline = %+v", result.Duration, result.Timeline)
+	}
+	if buf.String() != first+second {
+		t.Errorf("audio = %d bytes", buf.Len())
+	}
+}
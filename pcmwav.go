@@ -0,0 +1,64 @@
+package go_byte_tts
+
+import (
+	"io"
+
+	"github.com/jefferyjob/go-easy-utils/anyUtil"
+	"github.com/zmexing/go-byte-tts/audioinfo"
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// WithPCMToWav audio.encoding 为 pcm 时，写入 io.Writer 或文件的音频添加 WAV 头部，普通播放器可以直接打开
+// 头部按 audio.rate（默认 24000）、16 位、单声道生成，对 Synthesize 返回的 Audio 和请求参数没有影响
+// 分片拼接时 w 支持 Seek 则最后回写头部的长度，否则写入长度未知的流式头部，边合成边写入
+func WithPCMToWav() Option {
+	return func(g *GoTTS) {
+		g.pcmToWav = true
+	}
+}
+
+// pcmWavFormat 需要添加的 WAV 格式，未开启 [WithPCMToWav] 或编码不是 pcm 时返回 nil
+func (g *GoTTS) pcmWavFormat(params map[string]map[string]any) *internal.WavFormat {
+	if !g.pcmToWav || paramsEncoding(params) != audioinfo.FormatPCM {
+		return nil
+	}
+	return pcmFormat(params)
+}
+
+// paramsEncoding 请求的音频编码，未设置时为服务端默认的 pcm
+func paramsEncoding(params map[string]map[string]any) string {
+	if encoding := anyUtil.AnyToStr(params["audio"]["encoding"]); encoding != "" {
+		return encoding
+	}
+	return defaultEncoding
+}
+
+// pcmFormat 服务端返回的 pcm 格式：audio.rate（默认 24000）、16 位、单声道
+func pcmFormat(params map[string]map[string]any) *internal.WavFormat {
+	rate, _ := anyUtil.AnyToInt(params["audio"]["rate"])
+	if rate <= 0 {
+		rate = audioinfo.DefaultSampleRate
+	}
+	return internal.PCMWavFormat(rate, audioinfo.DefaultBitDepth, audioinfo.DefaultChannels)
+}
+
+// writeAudio 将单次合成的音频写入 w，需要时添加长度确定的 WAV 头部
+func (g *GoTTS) writeAudio(params map[string]map[string]any, audio []byte, w io.Writer) error {
+	if format := g.pcmWavFormat(params); format != nil {
+		if err := internal.WriteBytesToDisk(internal.WavHeader(format, uint32(len(audio))), w); err != nil {
+			return err
+		}
+	}
+	return internal.WriteBytesToDisk(audio, w)
+}
+
+// newAudioJoiner 按编码创建拼接器，需要时为 pcm 添加 WAV 头部，并处理分片边界，参见 [WithJoinSmoothing]
+func (g *GoTTS) newAudioJoiner(params map[string]map[string]any, w io.Writer) internal.AudioJoiner {
+	var joiner internal.AudioJoiner
+	if format := g.pcmWavFormat(params); format != nil {
+		joiner = internal.NewPCMWavJoiner(format, w)
+	} else {
+		joiner = internal.NewAudioJoiner(anyUtil.AnyToStr(params["audio"]["encoding"]), w)
+	}
+	return g.smoothJoiner(params, joiner)
+}
@@ -0,0 +1,90 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zmexing/go-byte-tts/audioinfo"
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func pcmWavParams(text, encoding string, rate int) map[string]map[string]any {
+	return map[string]map[string]any{
+		"audio":   {"voice_type": "BV406_V2_streaming", "encoding": encoding, "rate": rate},
+		"request": {"reqid": "reqid", "text": text, "operation": "query"},
+	}
+}
+
+// checkPCMWav 校验 WAV 格式和 data 块的长度，dataSize 为头部中记录的长度
+func checkPCMWav(t *testing.T, b []byte, rate int, pcm string, dataSize uint32) {
+	t.Helper()
+	info, err := audioinfo.ProbeWAV(b)
+	if err != nil {
+		t.Fatalf("ProbeWAV err = %v", err)
+	}
+	if info.SampleRate != rate || info.BitDepth != 16 || info.Channels != 1 || info.Bytes != len(pcm) {
+		t.Errorf("info = %+v", info)
+	}
+	if !strings.HasSuffix(string(b), pcm) {
+		t.Errorf("audio does not end with the pcm data")
+	}
+	if got := binary.LittleEndian.Uint32(b[40:44]); got != dataSize {
+		t.Errorf("data size = %#x, want %#x", got, dataSize)
+	}
+}
+
+func TestWithPCMToWav(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithPCMToWav())
+	ctx := context.Background()
+
+	// 单次合成的长度已知，直接写入完整的头部
+	var buf bytes.Buffer
+	if err := tts.TextToVoiceWriter(ctx, pcmWavParams("你好", "pcm", 16000), &buf); err != nil {
+		t.Fatal(err)
+	}
+	checkPCMWav(t, buf.Bytes(), 16000, "你好", uint32(len("你好")))
+
+	// 分片拼接写入文件时回写头部的长度
+	text := strings.Repeat("一", 300) + "。" + strings.Repeat("二", 100)
+	file, err := os.CreateTemp(t.TempDir(), "join-*.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := tts.TextToJoinVoiceDisk(pcmWavParams(text, "pcm", 0), file); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPCMWav(t, got, audioinfo.DefaultSampleRate, text, uint32(len(text)))
+
+	// 不支持 Seek 时使用长度未知的流式头部
+	rc, err := tts.TextToVoiceReader(ctx, pcmWavParams(text, "pcm", 8000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	got, err = io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPCMWav(t, got, 8000, text, 0xFFFFFFFF)
+
+	// 其他编码不受影响
+	buf.Reset()
+	if err := tts.TextToJoinVoiceWriter(ctx, pcmWavParams("你好", "mp3", 0), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "你好" {
+		t.Errorf("mp3 audio = %q", buf.String())
+	}
+}
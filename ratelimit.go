@@ -0,0 +1,74 @@
+package go_byte_tts
+
+import (
+	"context"
+	"errors"
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// ErrRateLimited 开启 FailFast 时，超出客户端限流直接返回该错误
+var ErrRateLimited = errors.New("tts: client rate limit exceeded")
+
+// RateLimit 单类接口的限流配置，QPS 小于等于0时不限流
+type RateLimit struct {
+	QPS   float64 // 每秒允许的请求数
+	Burst int     // 允许的突发请求数，小于1时按1处理
+}
+
+// RateLimits 各类接口的限流配置，同一个 GoTTS 的所有协程共享限流额度
+type RateLimits struct {
+	Synthesis RateLimit // 短文本语音合成，包括 TextToJoinVoiceDisk 的每个分片
+	Create    RateLimit // 长文本语音合成 任务创建
+	Query     RateLimit // 长文本语音合成 任务查询
+	FailFast  bool      // 超出限流时立即返回 ErrRateLimited，默认阻塞等待直到 ctx 结束
+}
+
+// DefaultRateLimits 默认限流配置，任务创建遵守服务端 10 QPS 的频率限制
+var DefaultRateLimits = RateLimits{
+	Create: RateLimit{QPS: 10, Burst: 1},
+}
+
+// WithRateLimits 设置客户端限流，默认使用 [DefaultRateLimits]
+func WithRateLimits(limits RateLimits) Option {
+	return func(g *GoTTS) {
+		g.rateLimits = limits
+	}
+}
+
+// rateLimiters 根据配置创建的限流器，未配置的接口为 nil
+type rateLimiters struct {
+	synthesis *internal.RateLimiter
+	create    *internal.RateLimiter
+	query     *internal.RateLimiter
+	failFast  bool
+}
+
+func newRateLimiters(limits RateLimits) rateLimiters {
+	return rateLimiters{
+		synthesis: newRateLimiter(limits.Synthesis),
+		create:    newRateLimiter(limits.Create),
+		query:     newRateLimiter(limits.Query),
+		failFast:  limits.FailFast,
+	}
+}
+
+func newRateLimiter(limit RateLimit) *internal.RateLimiter {
+	if limit.QPS <= 0 {
+		return nil
+	}
+	return internal.NewRateLimiter(limit.QPS, limit.Burst)
+}
+
+// waitRateLimit 获取一次请求的限流额度
+func (g *GoTTS) waitRateLimit(ctx context.Context, limiter *internal.RateLimiter) error {
+	if limiter == nil {
+		return nil
+	}
+	if g.limiters.failFast {
+		if !limiter.Allow() {
+			return ErrRateLimited
+		}
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
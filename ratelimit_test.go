@@ -0,0 +1,81 @@
+package go_byte_tts
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultRateLimitsCreate(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"}); err != nil {
+				t.Errorf("LongTextToVoiceCreate err = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 10 QPS 下4次创建至少需要 300ms
+	if elapsed := time.Since(start); elapsed < time.Millisecond*280 {
+		t.Errorf("4 creates took %v, want >= 300ms", elapsed)
+	}
+}
+
+func TestRateLimitsFailFast(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRateLimits(RateLimits{
+		Synthesis: RateLimit{QPS: 1, Burst: 1},
+		FailFast:  true,
+	}))
+
+	if err := tts.TextToVoiceDiskRequest(newTestRequest(), createTempFile(t)); err != nil {
+		t.Fatalf("first request err = %v", err)
+	}
+	err := tts.TextToVoiceDiskRequest(newTestRequest(), createTempFile(t))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if n := len(requestPaths(srv)); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+
+	// 任务创建和查询不受短文本限流影响
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	if _, err := tts.LongTextToVoiceId(created.TaskId); err != nil {
+		t.Errorf("LongTextToVoiceId err = %v", err)
+	}
+}
+
+func TestRateLimitsWaitContext(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRateLimits(RateLimits{
+		Query: RateLimit{QPS: 0.5, Burst: 1},
+	}))
+
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	if _, err := tts.LongTextToVoiceId(created.TaskId); err != nil {
+		t.Fatalf("first query err = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	_, err = tts.LongTextToVoiceIdContext(ctx, created.TaskId)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,139 @@
+package go_byte_tts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/zmexing/go-byte-tts/ssml"
+)
+
+// SynthesisRequest 短文本语音合成请求参数，对应 /api/v1/tts 的请求体
+// app 部分由 [GoTTS] 自动填充，无需设置
+type SynthesisRequest struct {
+	User    UserConfig    `json:"user"`
+	Audio   AudioConfig   `json:"audio"`
+	Request RequestConfig `json:"request"`
+}
+
+// UserConfig 用户信息
+type UserConfig struct {
+	Uid string `json:"uid,omitempty"` // 用户标识，可以传递用户真实的ID，方便问题定位
+}
+
+// AudioConfig 音频参数
+type AudioConfig struct {
+	VoiceType       string  `json:"voice_type"`                 // 音色代号
+	Emotion         string  `json:"emotion,omitempty"`          // 音色情感
+	Encoding        string  `json:"encoding,omitempty"`         // 音频编码格式：wav / pcm / ogg_opus / mp3，默认为 pcm
+	CompressionRate int     `json:"compression_rate,omitempty"` // opus格式时编码压缩比
+	Rate            int     `json:"rate,omitempty"`             // 音频采样率：8000 / 16000 / 24000，默认为 24000
+	SpeedRatio      float64 `json:"speed_ratio,omitempty"`      // 语速：[0.2,3]，默认为 1
+	VolumeRatio     float64 `json:"volume_ratio,omitempty"`     // 音量：[0.1,3]，默认为 1
+	PitchRatio      float64 `json:"pitch_ratio,omitempty"`      // 音高：[0.1,3]，默认为 1
+	Language        string  `json:"language,omitempty"`         // 语言类型
+}
+
+// RequestConfig 请求参数
+type RequestConfig struct {
+	Reqid           string `json:"reqid"`                      // 请求标识，为空时自动生成
+	Text            string `json:"text"`                       // 合成语音的文本
+	TextType        string `json:"text_type,omitempty"`        // 文本类型：plain / ssml，默认为 plain
+	SilenceDuration int    `json:"silence_duration,omitempty"` // 句尾静音时长，单位为ms，默认为125
+	Operation       string `json:"operation"`                  // 操作：query / submit，为空时使用 query
+	WithFrontend    int    `json:"with_frontend,omitempty"`    // 是否返回时间戳等前端信息，1为返回
+	FrontendType    string `json:"frontend_type,omitempty"`    // 前端信息类型，如 unitTson
+	SplitSentence   int    `json:"split_sentence,omitempty"`   // 是否开启复刻音色的分句
+}
+
+const (
+	// 默认操作类型
+	defaultOperation = "query"
+)
+
+var (
+	supportedEncodings = map[string]bool{"wav": true, "pcm": true, "ogg_opus": true, "mp3": true}
+	supportedRates     = map[int]bool{8000: true, 16000: true, 24000: true}
+	supportedTextTypes = map[string]bool{"plain": true, "ssml": true}
+	supportedOperation = map[string]bool{"query": true, "submit": true}
+)
+
+// Validate 校验请求参数
+func (r *SynthesisRequest) Validate() error {
+	if r == nil {
+		return errors.New("request cannot be nil")
+	}
+	if r.Audio.VoiceType == "" {
+		return errors.New("audio.voice_type cannot be empty")
+	}
+	if r.Request.Text == "" {
+		return errors.New("request.text cannot be empty")
+	}
+	if r.Audio.Encoding != "" && !supportedEncodings[r.Audio.Encoding] {
+		return fmt.Errorf("audio.encoding %q is not supported", r.Audio.Encoding)
+	}
+	if r.Audio.Rate != 0 && !supportedRates[r.Audio.Rate] {
+		return fmt.Errorf("audio.rate %d is not supported", r.Audio.Rate)
+	}
+	if err := checkRatio("audio.speed_ratio", r.Audio.SpeedRatio, 0.2, 3); err != nil {
+		return err
+	}
+	if err := checkRatio("audio.volume_ratio", r.Audio.VolumeRatio, 0.1, 3); err != nil {
+		return err
+	}
+	if err := checkRatio("audio.pitch_ratio", r.Audio.PitchRatio, 0.1, 3); err != nil {
+		return err
+	}
+	if r.Request.TextType != "" && !supportedTextTypes[r.Request.TextType] {
+		return fmt.Errorf("request.text_type %q is not supported", r.Request.TextType)
+	}
+	if r.Request.TextType == textTypeSSML {
+		if err := ssml.Validate(r.Request.Text); err != nil {
+			return fmt.Errorf("request.text: %w", err)
+		}
+	}
+	if r.Request.Operation != "" && !supportedOperation[r.Request.Operation] {
+		return fmt.Errorf("request.operation %q is not supported", r.Request.Operation)
+	}
+	if r.Request.SilenceDuration < 0 {
+		return errors.New("request.silence_duration cannot be negative")
+	}
+	// 音色目录中已有的音色校验情感和采样率，其他音色由服务端校验
+	return checkVoice(r.Audio.VoiceType, r.Audio.Emotion, r.Audio.Rate, false)
+}
+
+// Params 校验并转换为 [GoTTSInter] 使用的 map 参数
+// reqid 为空时自动生成，operation 为空时使用 query
+func (r *SynthesisRequest) Params() (map[string]map[string]any, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := *r
+	if c.Request.Reqid == "" {
+		c.Request.Reqid = uuid.NewString()
+	}
+	if c.Request.Operation == "" {
+		c.Request.Operation = defaultOperation
+	}
+
+	jsonStr, err := json.Marshal(&c)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]map[string]any)
+	if err := json.Unmarshal(jsonStr, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func checkRatio(name string, v, lo, hi float64) error {
+	if v == 0 {
+		return nil
+	}
+	if v < lo || v > hi {
+		return fmt.Errorf("%s %v out of range [%v,%v]", name, v, lo, hi)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package go_byte_tts
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func newTestRequest() *SynthesisRequest {
+	return &SynthesisRequest{
+		User: UserConfig{Uid: "uid"},
+		Audio: AudioConfig{
+			VoiceType:   "BV406_V2_streaming",
+			Encoding:    "mp3",
+			SpeedRatio:  1.0,
+			VolumeRatio: 1.0,
+			PitchRatio:  1.0,
+		},
+		Request: RequestConfig{
+			Text:     "中华兴盛，辛有斌哥。How are you",
+			TextType: "plain",
+		},
+	}
+}
+
+func TestSynthesisRequestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(r *SynthesisRequest)
+		ok     bool
+	}{
+		{"ok", func(r *SynthesisRequest) {}, true},
+		{"empty voice", func(r *SynthesisRequest) { r.Audio.VoiceType = "" }, false},
+		{"empty text", func(r *SynthesisRequest) { r.Request.Text = "" }, false},
+		{"bad encoding", func(r *SynthesisRequest) { r.Audio.Encoding = "aac" }, false},
+		{"bad rate", func(r *SynthesisRequest) { r.Audio.Rate = 44100 }, false},
+		{"speed too fast", func(r *SynthesisRequest) { r.Audio.SpeedRatio = 3.5 }, false},
+		{"volume too low", func(r *SynthesisRequest) { r.Audio.VolumeRatio = 0.05 }, false},
+		{"bad text type", func(r *SynthesisRequest) { r.Request.TextType = "html" }, false},
+		{"bad operation", func(r *SynthesisRequest) { r.Request.Operation = "stream" }, false},
+		{"unsupported emotion", func(r *SynthesisRequest) { r.Audio.Emotion = "happy" }, false},
+		{"general emotion", func(r *SynthesisRequest) { r.Audio.Emotion = "通用" }, true},
+		{"voice not in catalog", func(r *SynthesisRequest) { r.Audio.VoiceType = "BV999_streaming" }, true},
+	}
+	for _, c := range cases {
+		r := newTestRequest()
+		c.modify(r)
+		err := r.Validate()
+		if (err == nil) != c.ok {
+			t.Errorf("%s: Validate() err = %v, want ok = %v", c.name, err, c.ok)
+		}
+	}
+}
+
+func TestSynthesisRequestParams(t *testing.T) {
+	params, err := newTestRequest().Params()
+	if err != nil {
+		t.Fatalf("Params() err = %v", err)
+	}
+
+	if params["audio"]["voice_type"] != "BV406_V2_streaming" {
+		t.Errorf("voice_type = %v", params["audio"]["voice_type"])
+	}
+	if params["audio"]["speed_ratio"] != 1.0 {
+		t.Errorf("speed_ratio = %v", params["audio"]["speed_ratio"])
+	}
+	if params["request"]["operation"] != "query" {
+		t.Errorf("operation = %v, want query", params["request"]["operation"])
+	}
+	if reqid, _ := params["request"]["reqid"].(string); reqid == "" {
+		t.Error("reqid should be generated")
+	}
+	if _, ok := params["audio"]["emotion"]; ok {
+		t.Error("empty emotion should be omitted")
+	}
+
+	// 序列化结果与请求体字段保持一致
+	b, _ := json.Marshal(params)
+	var back SynthesisRequest
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatalf("Unmarshal err = %v", err)
+	}
+	if back.Request.Text != newTestRequest().Request.Text {
+		t.Errorf("text = %q", back.Request.Text)
+	}
+}
@@ -0,0 +1,161 @@
+package go_byte_tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy 重试策略
+// 并发超限、服务繁忙等临时错误，以及连接被拒绝、超时、连接中断等临时网络错误会按指数退避重试，每次重试使用新的 reqid
+// 域名不存在、证书校验失败等不会自行恢复的网络错误不重试
+// LongTextToVoiceCreate 不是幂等的，超时或连接中断时服务端可能已经创建了任务，
+// 只在接口返回临时错误或连接被拒绝等请求没有发出的情况下重试，避免重复创建任务
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数（包含首次请求），小于等于1时不重试
+	InitialBackoff time.Duration // 首次重试前的等待时间
+	MaxBackoff     time.Duration // 单次等待时间上限，为0时不限制
+	Multiplier     float64       // 每次重试等待时间的倍数，小于1时按1处理
+	Jitter         float64       // 等待时间的随机抖动比例，取值 [0,1]
+	RetryableCodes []int         // 需要重试的接口返回码，为空时按 [APIError.Retryable] 判断
+}
+
+// DefaultRetryPolicy 推荐的重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond * 200,
+	MaxBackoff:     time.Second * 5,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetryPolicy 设置重试策略，默认不重试
+// 作用于 TextToVoice、TextToJoinVoiceDisk 的每个分片、LongTextToVoiceCreate 和 LongTextToVoiceId
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(g *GoTTS) {
+		g.retryPolicy = policy
+	}
+}
+
+// backoff 第 attempt 次重试前的等待时间，attempt 从1开始
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		wait *= multiplier
+		if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		wait += wait * jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(wait)
+}
+
+// retryable 判断幂等请求的错误是否可以重试：接口返回的临时错误、请求没有发出的网络错误，以及超时、连接中断
+func (p RetryPolicy) retryable(err error) bool {
+	if retry, ok := p.apiRetryable(err); ok {
+		return retry
+	}
+	return notSent(err) || interrupted(err)
+}
+
+// retryableCreate 判断创建长文本任务的错误是否可以重试
+// 超时、连接中断时服务端可能已经创建了任务，重试会重复创建并计费，因此只重试确定没有创建任务的错误
+func (p RetryPolicy) retryableCreate(err error) bool {
+	if retry, ok := p.apiRetryable(err); ok {
+		return retry
+	}
+	return notSent(err)
+}
+
+// apiRetryable 判断 ctx 结束和接口返回的错误，其他错误返回 ok 为 false
+func (p RetryPolicy) apiRetryable(err error) (retry bool, ok bool) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, true
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false, false
+	}
+	if len(p.RetryableCodes) > 0 && apiErr.Code != 0 {
+		for _, code := range p.RetryableCodes {
+			if code == apiErr.Code {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return apiErr.Retryable(), true
+}
+
+// notSent 请求还没有发出的临时网络错误：连接被拒绝、DNS 超时或临时失败
+// 域名不存在、证书校验失败、地址格式错误等不会自行恢复的错误不重试
+func notSent(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound && (dnsErr.IsTimeout || dnsErr.IsTemporary)
+	}
+	return false
+}
+
+// interrupted 请求可能已经发出的临时网络错误：超时、连接被重置或提前关闭
+func interrupted(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retry 按重试策略执行 fn，attempt 从0开始
+// 等待期间 ctx 结束，或者剩余时间不足以等待下一次重试时，直接返回最后一次的错误
+func (g *GoTTS) retry(ctx context.Context, fn func(attempt int) error) error {
+	return g.retryIf(ctx, g.retryPolicy.retryable, fn)
+}
+
+// retryIf 与 retry 相同，由 retryable 判断错误是否可以重试
+func (g *GoTTS) retryIf(ctx context.Context, retryable func(error) bool, fn func(attempt int) error) error {
+	p := g.retryPolicy
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn(attempt)
+		if err == nil || attempt+1 >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.backoff(attempt + 1)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
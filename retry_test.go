@@ -0,0 +1,227 @@
+package go_byte_tts
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Millisecond * 100, MaxBackoff: time.Millisecond * 350, Multiplier: 2}
+	want := []time.Duration{100, 200, 350, 350}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := p.backoff(1)
+		if got < time.Millisecond*50 || got > time.Millisecond*150 {
+			t.Fatalf("backoff with jitter = %v, out of range", got)
+		}
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	p := RetryPolicy{}
+	if !p.retryable(newAPIError(http.StatusOK, 3005, "", "")) {
+		t.Error("3005 should be retryable")
+	}
+	if p.retryable(newAPIError(http.StatusOK, 3011, "", "")) {
+		t.Error("3011 should not be retryable")
+	}
+	if p.retryable(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)) {
+		t.Error("context error should not be retryable")
+	}
+	if !p.retryable(io.ErrUnexpectedEOF) {
+		t.Error("unexpected EOF should be retryable")
+	}
+
+	refused := &url.Error{Op: "Post", URL: "http://127.0.0.1:1", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+	timeout := &url.Error{Op: "Post", URL: "http://127.0.0.1:1", Err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}
+	cases := []struct {
+		name              string
+		err               error
+		retryable, create bool
+	}{
+		{"connection refused", fmt.Errorf("http request failed: %w", refused), true, true},
+		{"dns timeout", timeout, true, true},
+		{"nxdomain", &url.Error{Op: "Post", URL: "http://x.invalid", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}, false, false},
+		{"bad scheme", &url.Error{Op: "Post", URL: "ftp://x", Err: errors.New("unsupported protocol scheme")}, false, false},
+		{"tls verify", &url.Error{Op: "Post", URL: "https://x", Err: x509.UnknownAuthorityError{}}, false, false},
+		{"timeout", &url.Error{Op: "Post", URL: "http://x", Err: timeoutError{}}, true, false},
+		{"eof", &url.Error{Op: "Post", URL: "http://x", Err: io.EOF}, true, false},
+		{"api busy", newAPIError(http.StatusOK, 3005, "", ""), true, true},
+	}
+	for _, c := range cases {
+		if got := p.retryable(c.err); got != c.retryable {
+			t.Errorf("%s: retryable = %v, want %v", c.name, got, c.retryable)
+		}
+		if got := p.retryableCreate(c.err); got != c.create {
+			t.Errorf("%s: retryableCreate = %v, want %v", c.name, got, c.create)
+		}
+	}
+
+	p.RetryableCodes = []int{3011}
+	if !p.retryable(newAPIError(http.StatusOK, 3011, "", "")) {
+		t.Error("3011 should be retryable with RetryableCodes")
+	}
+	if p.retryable(newAPIError(http.StatusOK, 3005, "", "")) {
+		t.Error("3005 should not be retryable with RetryableCodes")
+	}
+}
+
+// timeoutError 模拟读取响应超时的网络错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// newBusyServer 前 failures 次短文本合成返回服务繁忙，之后返回 "audio"
+func newBusyServer(t *testing.T, failures int) *bytettstest.Server {
+	srv := newTestServer(t)
+	for i := 0; i < failures; i++ {
+		srv.EnqueueTTS(bytettstest.Response{Code: 3005, Message: "server busy"})
+	}
+	srv.EnqueueTTS(bytettstest.Response{Audio: []byte("audio")})
+	return srv
+}
+
+// requestReqids 发送到 path 的每个请求的 reqid，短文本为 request.reqid，长文本为 reqid
+func requestReqids(srv *bytettstest.Server, path string) []string {
+	var reqids []string
+	for _, r := range srv.RequestsTo(path) {
+		var body struct {
+			Reqid   string `json:"reqid"`
+			Request struct {
+				Reqid string `json:"reqid"`
+			} `json:"request"`
+		}
+		_ = r.JSON(&body)
+		if body.Request.Reqid != "" {
+			body.Reqid = body.Request.Reqid
+		}
+		reqids = append(reqids, body.Reqid)
+	}
+	return reqids
+}
+
+func TestTextToVoiceRetry(t *testing.T) {
+	srv := newBusyServer(t, 2)
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRetryPolicy(policy))
+
+	req := newTestRequest()
+	req.Request.Reqid = "first"
+	outFile := createTempFile(t)
+	if err := tts.TextToVoiceDiskRequest(req, outFile); err != nil {
+		t.Fatalf("TextToVoiceDiskRequest err = %v", err)
+	}
+	if got := readTempFile(t, outFile); string(got) != "audio" {
+		t.Errorf("audio = %q", got)
+	}
+
+	reqids := requestReqids(srv, bytettstest.PathTTS)
+	if len(reqids) != 3 || reqids[0] != "first" {
+		t.Fatalf("reqids = %v", reqids)
+	}
+	if reqids[1] == reqids[0] || reqids[2] == reqids[1] {
+		t.Errorf("retry should use a new reqid, got %v", reqids)
+	}
+}
+
+func TestTextToVoiceRetryExhausted(t *testing.T) {
+	srv := newBusyServer(t, 5)
+	policy := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRetryPolicy(policy))
+
+	err := tts.TextToVoiceDiskRequest(newTestRequest(), createTempFile(t))
+	if !errors.Is(err, ErrServerBusy) {
+		t.Fatalf("err = %v, want ErrServerBusy", err)
+	}
+	if n := len(requestReqids(srv, bytettstest.PathTTS)); n != 2 {
+		t.Errorf("attempts = %d, want 2", n)
+	}
+}
+
+func TestTextToVoiceRetryRespectsDeadline(t *testing.T) {
+	srv := newBusyServer(t, 5)
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	start := time.Now()
+	err := tts.TextToVoiceDiskRequestContext(ctx, newTestRequest(), createTempFile(t))
+	if !errors.Is(err, ErrServerBusy) {
+		t.Fatalf("err = %v, want ErrServerBusy", err)
+	}
+	if time.Since(start) > time.Millisecond*500 {
+		t.Errorf("retry did not respect the deadline, took %v", time.Since(start))
+	}
+	if n := len(requestReqids(srv, bytettstest.PathTTS)); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+}
+
+func TestLongTextToVoiceCreateRetry(t *testing.T) {
+	srv := newTestServer(t)
+	srv.EnqueueSubmit(bytettstest.Response{Status: http.StatusInternalServerError, Code: 50000, Message: "internal error"})
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRetryPolicy(policy))
+	res, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	if res.TaskId != "task-1" {
+		t.Errorf("task id = %q", res.TaskId)
+	}
+	if reqids := requestReqids(srv, bytettstest.PathSubmit); len(reqids) != 2 || reqids[0] == reqids[1] {
+		t.Errorf("reqids = %v", reqids)
+	}
+}
+
+func TestLongTextToVoiceCreateNoRetryAfterSend(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		// 收到请求后直接断开连接，客户端无法知道任务是否已经创建
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithRetryPolicy(policy))
+	if _, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"}); err == nil {
+		t.Fatal("expected error")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("requests = %d, create should not be retried after the request was sent", requests)
+	}
+}
@@ -0,0 +1,50 @@
+package go_byte_tts
+
+import (
+	"time"
+
+	"github.com/zmexing/go-byte-tts/audioinfo"
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// 默认的静音阈值，约 -40 dBFS
+const defaultSilenceThreshold = 0.01
+
+// JoinSmoothing 超长文本分片拼接时对 pcm 和 wav 音频的处理
+// 裁剪分片边界处的静音后，在分片之间插入固定的停顿或交叉淡化，使分片之间的间隔一致
+// 第一个分片的开头和最后一个分片的结尾保持不变，mp3 和 ogg_opus 不做处理
+type JoinSmoothing struct {
+	Threshold float64       // 静音的能量阈值，为相对满幅的 RMS（0~1），默认为 0.01
+	Pause     time.Duration // 分片之间插入的静音，为0时分片直接相连
+	Crossfade time.Duration // 分片之间交叉淡化的时长，大于0时忽略 Pause
+}
+
+// WithJoinSmoothing 分片拼接时裁剪边界的静音并插入一致的停顿或交叉淡化，参见 [JoinSmoothing]
+// [JoinResult] 和 [Timeline] 中的时间按处理后的音频计算
+func WithJoinSmoothing(s JoinSmoothing) Option {
+	return func(g *GoTTS) {
+		if s.Threshold <= 0 {
+			s.Threshold = defaultSilenceThreshold
+		}
+		g.joinSmoothing = &s
+	}
+}
+
+// smoothJoiner 开启 [WithJoinSmoothing] 且编码为 pcm 或 wav 时，在 joiner 之前处理分片边界
+func (g *GoTTS) smoothJoiner(params map[string]map[string]any, joiner internal.AudioJoiner) internal.AudioJoiner {
+	if g.joinSmoothing == nil {
+		return joiner
+	}
+	opts := internal.SmoothOptions{
+		Threshold: g.joinSmoothing.Threshold,
+		Pause:     g.joinSmoothing.Pause,
+		Crossfade: g.joinSmoothing.Crossfade,
+	}
+	switch paramsEncoding(params) {
+	case audioinfo.FormatPCM:
+		return internal.NewSmoothJoiner(pcmFormat(params), opts, joiner)
+	case audioinfo.FormatWAV:
+		return internal.NewSmoothJoiner(nil, opts, joiner)
+	}
+	return joiner
+}
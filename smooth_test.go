@@ -0,0 +1,78 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+// testTone 生成 24kHz 16 位单声道 pcm，正数为有声音的毫秒数，负数为静音的毫秒数
+func testTone(parts ...int) []byte {
+	var b []byte
+	for _, ms := range parts {
+		v := int16(8000)
+		if ms < 0 {
+			v, ms = 0, -ms
+		}
+		samples := make([]byte, ms*24*2)
+		for i := 0; i < len(samples)/2; i++ {
+			s := v
+			if i%2 == 1 {
+				s = -v
+			}
+			binary.LittleEndian.PutUint16(samples[2*i:], uint16(s))
+		}
+		b = append(b, samples...)
+	}
+	return b
+}
+
+func TestWithJoinSmoothing(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(
+		bytettstest.Response{Audio: testTone(-50, 500, -300), Frontend: `{"words":[{"word":"一","start_time":0.05,"end_time":0.55}]}`},
+		bytettstest.Response{Audio: testTone(-200, 400, -100), Frontend: `{"words":[{"word":"二","start_time":0.2,"end_time":0.6}]}`},
+	)
+	// 模拟服务按请求顺序返回分片，串行合成
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithMaxConcurrency(1),
+		WithJoinSmoothing(JoinSmoothing{Pause: 100 * time.Millisecond}))
+
+	params := map[string]map[string]any{
+		"audio":   {"voice_type": "BV406_V2_streaming", "encoding": "pcm"},
+		"request": {"reqid": "reqid", "text": strings.Repeat("一", 300) + "。" + strings.Repeat("二", 100), "operation": "query"},
+	}
+	var buf bytes.Buffer
+	result, err := tts.TextToJoinVoiceResult(context.Background(), params, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一个分片的开头和最后一个分片的结尾保持不变，中间的静音统一为 100ms
+	if want := testTone(-50, 500, -100, 400, -100); !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("audio = %d bytes, want %d", buf.Len(), len(want))
+	}
+	ms := time.Millisecond
+	if len(result.Chunks) != 2 || result.Chunks[1].Offset != 650*ms || result.Chunks[0].Duration != 550*ms ||
+		result.Chunks[1].Duration != 500*ms || result.Duration != 1150*ms {
+		t.Errorf("result = %+v", result)
+	}
+	// 时间戳按裁剪后的位置偏移
+	if w := result.Timeline.Words; len(w) != 2 || w[1].Start != 650*ms || w[1].End != 1050*ms {
+		t.Errorf("words = %+v", w)
+	}
+
+	// mp3 不做处理
+	srv.EnqueueTTS(bytettstest.Response{Audio: []byte("mp3")})
+	params["audio"]["encoding"] = "mp3"
+	params["request"]["text"] = "你好"
+	buf.Reset()
+	if err := tts.TextToJoinVoiceWriter(context.Background(), params, &buf); err != nil || buf.String() != "mp3" {
+		t.Errorf("mp3 audio = %q, %v", buf.String(), err)
+	}
+}
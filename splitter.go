@@ -0,0 +1,98 @@
+package go_byte_tts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zmexing/go-byte-tts/internal"
+	"github.com/zmexing/go-byte-tts/ssml"
+)
+
+const (
+	// 单次合成接口的文本长度上限
+	maxTextBytes = 1024
+)
+
+// Splitter 超长文本的分片策略，每个分片不能超过 maxBytes 字节
+type Splitter interface {
+	Split(text string, maxBytes int) []string
+}
+
+// SplitterFunc 函数形式的 Splitter
+type SplitterFunc func(text string, maxBytes int) []string
+
+func (f SplitterFunc) Split(text string, maxBytes int) []string {
+	return f(text, maxBytes)
+}
+
+var (
+	// SentenceSplitter 默认的分片策略，依次优先在换行、句末标点（。！？.!?）、分句标点（，；,;）和空白处切分
+	SentenceSplitter Splitter = paragraphSplitter(internal.SplitSentences)
+	// ByteSplitter 只按字节长度在字符边界切分
+	ByteSplitter Splitter = paragraphSplitter(internal.SplitText)
+)
+
+// paragraphSplitter 内置的分片策略，分片不会跨越空行分隔的段落
+// 修改一段文本不会影响其他段落的分片，开启缓存时只需要重新合成修改过的段落
+type paragraphSplitter func(text string, maxBytes int) []string
+
+func (f paragraphSplitter) Split(text string, maxBytes int) []string {
+	var chunks []string
+	for _, paragraph := range splitParagraphs(text) {
+		chunks = append(chunks, f(paragraph, maxBytes)...)
+	}
+	return chunks
+}
+
+// WithSplitter 设置 TextToJoinVoiceDisk 的分片策略，默认为 SentenceSplitter
+// 自定义的分片策略直接收到完整的文本
+func WithSplitter(splitter Splitter) Option {
+	return func(g *GoTTS) {
+		g.splitter = splitter
+	}
+}
+
+// splitText 按分片策略切分文本，丢弃空分片，超出长度上限时返回错误
+// SSML 文本使用 [ssml.Split] 切分，每个分片都是标签平衡的完整文档
+func (g *GoTTS) splitText(text string, isSSML bool) ([]string, error) {
+	if isSSML {
+		return ssml.Split(text, maxTextBytes)
+	}
+
+	var chunks []string
+	for _, chunk := range g.splitter.Split(text, maxTextBytes) {
+		if chunk == "" {
+			continue
+		}
+		if len(chunk) > maxTextBytes {
+			return nil, fmt.Errorf("splitter returned a chunk of %d bytes, exceeds the limit of %d", len(chunk), maxTextBytes)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// splitParagraphs 按空行切成段落，空行保留在上一段的末尾，拼接后与原文相同
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	start, blank := 0, false
+	for i := 0; i < len(text); {
+		end := strings.IndexByte(text[i:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += i + 1
+		}
+		if strings.TrimSpace(text[i:end]) == "" {
+			blank = strings.TrimSpace(text[start:i]) != ""
+		} else if blank {
+			paragraphs = append(paragraphs, text[start:i])
+			start, blank = i, false
+		}
+		i = end
+	}
+	if start < len(text) {
+		paragraphs = append(paragraphs, text[start:])
+	}
+	return paragraphs
+}
@@ -0,0 +1,90 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func TestWithSplitter(t *testing.T) {
+	srv := newTestServer(t)
+	calls := 0
+	splitter := SplitterFunc(func(text string, maxBytes int) []string {
+		calls++
+		return strings.SplitAfter(text, "。")
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithSplitter(splitter))
+
+	req := newTestRequest()
+	req.Request.Text = "昏黑的夜色中。月光如水洒落。静谧而神秘。"
+	outFile := createTempFile(t)
+	if err := tts.TextToJoinVoiceDiskRequest(req, outFile); err != nil {
+		t.Fatalf("TextToJoinVoiceDiskRequest err = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("splitter calls = %d, want 1", calls)
+	}
+	// SplitAfter 结尾的空字符串被忽略
+	if n := len(requestPaths(srv)); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if got := readTempFile(t, outFile); string(got) != req.Request.Text {
+		t.Errorf("audio = %q, want %q", got, req.Request.Text)
+	}
+}
+
+func TestWithSplitterOversizedChunk(t *testing.T) {
+	srv := newTestServer(t)
+	splitter := SplitterFunc(func(text string, maxBytes int) []string {
+		return []string{text}
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithSplitter(splitter))
+
+	req := newTestRequest()
+	req.Request.Text = strings.Repeat("月光如水洒落。", 100)
+	if err := tts.TextToJoinVoiceDiskRequest(req, createTempFile(t)); err == nil {
+		t.Fatal("expected error for oversized chunk")
+	}
+	if n := len(requestPaths(srv)); n != 0 {
+		t.Errorf("requests = %d, want 0", n)
+	}
+}
+
+func TestSplitTextParagraphs(t *testing.T) {
+	first := strings.Repeat("第一段第一行。\n", 20) + "\n \n"
+	second := strings.Repeat("第二段。", 10) + "\n"
+	text := "\n" + first + second
+	g := newOfflineTTS(t).(*GoTTS)
+
+	// 空行保留在上一段末尾，段落内的多行合并为一个分片
+	chunks, err := g.splitText(text, false)
+	if err != nil || len(chunks) != 2 || chunks[0] != "\n"+first || chunks[1] != second {
+		t.Errorf("chunks = %q, %v", chunks, err)
+	}
+	if got := strings.Join(splitParagraphs(text), ""); got != text {
+		t.Errorf("paragraphs joined = %q", got)
+	}
+}
+
+func TestJoinShortLinesOneRequest(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithSplitter(ByteSplitter))
+
+	req := newTestRequest()
+	req.Audio.Encoding = "pcm"
+	req.Request.Text = strings.Repeat("月光如水洒落。\n", 40)
+	var buf bytes.Buffer
+	if err := tts.TextToJoinVoiceRequestWriter(context.Background(), req, &buf); err != nil {
+		t.Fatalf("TextToJoinVoiceRequestWriter err = %v", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+	if buf.String() != req.Request.Text {
+		t.Errorf("audio = %q", buf.String())
+	}
+}
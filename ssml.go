@@ -0,0 +1,40 @@
+package go_byte_tts
+
+import (
+	"fmt"
+
+	"github.com/jefferyjob/go-easy-utils/anyUtil"
+	"github.com/zmexing/go-byte-tts/ssml"
+)
+
+// 文本类型 request.text_type 为 SSML
+const textTypeSSML = "ssml"
+
+// SSMLDocument 可以直接作为 request.text 的 SSML 文档，例如 [ssml.Builder]
+// 发送前调用 SSML 生成文本，并自动设置 request.text_type 为 ssml
+type SSMLDocument interface {
+	SSML() (string, error)
+}
+
+// checkParamsSSML 展开 request.text 中的 SSML 文档，并校验 text_type 为 ssml 的文本
+func checkParamsSSML(params map[string]map[string]any) error {
+	if doc, ok := params["request"]["text"].(SSMLDocument); ok {
+		text, err := doc.SSML()
+		if err != nil {
+			return fmt.Errorf("request.text: %w", err)
+		}
+		params["request"]["text"] = text
+		params["request"]["text_type"] = textTypeSSML
+		return nil
+	}
+	if isSSML(params) {
+		if err := ssml.Validate(anyUtil.AnyToStr(params["request"]["text"])); err != nil {
+			return fmt.Errorf("request.text: %w", err)
+		}
+	}
+	return nil
+}
+
+func isSSML(params map[string]map[string]any) bool {
+	return anyUtil.AnyToStr(params["request"]["text_type"]) == textTypeSSML
+}
@@ -0,0 +1,168 @@
+package ssml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// 切分文本时每个分片至少能容纳的字节数，保证转义后的单个字符可以放入分片
+const minTextBytes = 8
+
+// Split 解析 SSML 文档并切分为不超过 maxBytes 字节的分片，参见 [Builder.Split]
+func Split(s string, maxBytes int) ([]string, error) {
+	b, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return b.Split(maxBytes)
+}
+
+// Split 将文档切分为不超过 maxBytes 字节的分片，每个分片都是完整的 <speak> 文档
+// 跨分片的 <prosody>、<emphasis> 在分片末尾闭合，并在下一个分片开头重新打开，保证标签平衡
+// 文本优先在换行、句子和标点处切分，<break>、<say-as>、<phoneme>、<sub> 不会被切开
+func (b *Builder) Split(maxBytes int) ([]string, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	c := &chunker{root: b.root, maxBytes: maxBytes}
+	c.reset()
+	for _, seg := range flatten(b.root, nil, nil) {
+		if err := c.add(seg); err != nil {
+			return nil, err
+		}
+	}
+	c.flush()
+	return c.chunks, nil
+}
+
+// segment 文本节点或不可切分的标签，以及包含它的 <prosody>、<emphasis>
+type segment struct {
+	path []*node
+	node *node
+}
+
+func flatten(n *node, path []*node, out []segment) []segment {
+	for _, c := range n.children {
+		if c.name != "" && rules[c.name].content == contentMixed {
+			out = flatten(c, append(path[:len(path):len(path)], c), out)
+			continue
+		}
+		out = append(out, segment{path: path, node: c})
+	}
+	return out
+}
+
+// chunker 按顺序把片段装入分片，放不下时开始新的分片
+type chunker struct {
+	root     *node
+	maxBytes int
+	chunks   []string
+
+	sb      strings.Builder
+	path    []*node // 当前分片中已打开的标签
+	content bool    // 当前分片是否有非空白的内容
+}
+
+func (c *chunker) reset() {
+	c.sb.Reset()
+	c.sb.WriteString(openTag(c.root))
+	c.path = nil
+	c.content = false
+}
+
+// flush 闭合当前分片的标签，只有空白的分片会被丢弃
+func (c *chunker) flush() {
+	if c.content {
+		c.sb.WriteString(transition(c.path, nil))
+		c.sb.WriteString(closeTag(c.root))
+		c.chunks = append(c.chunks, c.sb.String())
+	}
+	c.reset()
+}
+
+// fits 在当前分片追加 s 并闭合后是否不超过上限
+func (c *chunker) fits(path []*node, s string) bool {
+	n := c.sb.Len() + len(transition(c.path, path)) + len(s) + len(transition(path, nil)) + len(closeTag(c.root))
+	return n <= c.maxBytes
+}
+
+func (c *chunker) write(path []*node, s string, content bool) {
+	c.sb.WriteString(transition(c.path, path))
+	c.sb.WriteString(s)
+	c.path = path
+	c.content = c.content || content
+}
+
+func (c *chunker) add(seg segment) error {
+	var sb strings.Builder
+	render(&sb, seg.node)
+	s := sb.String()
+	content := hasContent(seg.node)
+
+	if c.fits(seg.path, s) {
+		c.write(seg.path, s, content)
+		return nil
+	}
+	if !content {
+		// 放不下的空白直接丢弃
+		return nil
+	}
+	// 开始新的分片，当前分片只有空白时直接丢弃
+	c.flush()
+	if c.fits(seg.path, s) {
+		c.write(seg.path, s, content)
+		return nil
+	}
+	if seg.node.name != "" {
+		return fmt.Errorf("ssml: <%s> of %d bytes does not fit in a chunk of %d bytes", seg.node.name, len(s), c.maxBytes)
+	}
+
+	// 空分片也放不下的文本，按剩余空间切分
+	budget := c.maxBytes - c.sb.Len() - len(transition(c.path, seg.path)) - len(transition(seg.path, nil)) - len(closeTag(c.root))
+	if budget < minTextBytes {
+		return fmt.Errorf("ssml: tags leave %d bytes for text in a chunk of %d bytes", budget, c.maxBytes)
+	}
+	for i, piece := range splitEscaped(seg.node.text, budget, budget) {
+		if i > 0 {
+			c.flush()
+		}
+		c.write(seg.path, piece, true)
+	}
+	return nil
+}
+
+// splitEscaped 切分文本并转义，转义后超出 limit 的片段缩小切分长度后重新切分
+func splitEscaped(text string, limit, size int) []string {
+	var out []string
+	for _, piece := range internal.SplitSentences(text, size) {
+		escaped := textEscaper.Replace(piece)
+		if len(escaped) <= limit {
+			out = append(out, escaped)
+			continue
+		}
+		smaller := size - (len(escaped) - limit)
+		if smaller < 1 {
+			smaller = 1
+		}
+		out = append(out, splitEscaped(piece, limit, smaller)...)
+	}
+	return out
+}
+
+// transition 从已打开的 from 切换到 to 需要的闭合和开始标签
+func transition(from, to []*node) string {
+	k := 0
+	for k < len(from) && k < len(to) && from[k] == to[k] {
+		k++
+	}
+	var sb strings.Builder
+	for i := len(from) - 1; i >= k; i-- {
+		sb.WriteString(closeTag(from[i]))
+	}
+	for _, n := range to[k:] {
+		sb.WriteString(openTag(n))
+	}
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+package ssml
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// plainText 去掉标签并还原转义，用于比较切分前后的文本
+func plainText(s string) string {
+	s = tags.ReplaceAllString(s, "")
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(s)
+}
+
+func TestSplit(t *testing.T) {
+	doc := New().Text(strings.Repeat("开场白。", 10)).
+		Prosody(Prosody{Rate: "slow"}, func(b *Builder) {
+			b.Text(strings.Repeat("慢慢地说，", 30)).
+				SayAs("13800138000", InterpretTelephone, "").
+				Text(strings.Repeat("R&D <团队> 很重要。", 10))
+		}).
+		Break(300*time.Millisecond).
+		Emphasis(EmphasisStrong, strings.Repeat("重点。", 10))
+	whole := doc.String()
+
+	const maxBytes = 200
+	chunks, err := doc.Split(maxBytes)
+	if err != nil {
+		t.Fatalf("Split err = %v", err)
+	}
+	if len(chunks) < 3 {
+		t.Fatalf("chunks = %d, want several", len(chunks))
+	}
+
+	var text strings.Builder
+	for i, c := range chunks {
+		if len(c) > maxBytes {
+			t.Errorf("chunk %d has %d bytes", i, len(c))
+		}
+		if err := Validate(c); err != nil {
+			t.Errorf("chunk %d is not balanced: %v\n%s", i, err, c)
+		}
+		text.WriteString(plainText(c))
+	}
+	if text.String() != plainText(whole) {
+		t.Errorf("text changed after split:\n%s\nwant\n%s", text.String(), plainText(whole))
+	}
+
+	// 跨分片的 <prosody> 在下一个分片重新打开，<say-as> 不会被切开
+	reopened := 0
+	for _, c := range chunks {
+		if strings.HasPrefix(c, `<speak><prosody rate="slow">`) {
+			reopened++
+		}
+		if strings.Contains(c, "<say-as") && !strings.Contains(c, `<say-as interpret-as="telephone">13800138000</say-as>`) {
+			t.Errorf("say-as was split: %s", c)
+		}
+	}
+	if reopened < 2 {
+		t.Errorf("prosody reopened in %d chunks", reopened)
+	}
+}
+
+func TestSplitShort(t *testing.T) {
+	s := `<speak xml:lang="zh-CN"><prosody rate="fast">你好</prosody></speak>`
+	chunks, err := Split(s, 1024)
+	if err != nil || len(chunks) != 1 || chunks[0] != s {
+		t.Errorf("Split = %q, %v", chunks, err)
+	}
+
+	// 分片保留 <speak> 的属性
+	chunks, err = Split(`<speak xml:lang="zh-CN">`+strings.Repeat("你好。", 20)+`</speak>`, 60)
+	if err != nil || len(chunks) < 2 {
+		t.Fatalf("Split = %q, %v", chunks, err)
+	}
+	for _, c := range chunks {
+		if !strings.HasPrefix(c, `<speak xml:lang="zh-CN">`) {
+			t.Errorf("chunk = %s", c)
+		}
+	}
+}
+
+func TestSplitTooSmall(t *testing.T) {
+	if _, err := Split(`<speak><sub alias="世界贸易组织">WTO</sub></speak>`, 30); err == nil {
+		t.Error("element larger than a chunk should fail")
+	}
+	if _, err := Split(`<speak>你好`, 1024); err == nil {
+		t.Error("invalid document should fail")
+	}
+}
@@ -0,0 +1,228 @@
+// Package ssml 构建和校验字节语音合成接口支持的 SSML 文档
+//
+// 支持的标签：<speak>、<break>、<prosody>、<say-as>、<phoneme>、<sub>、<emphasis>，
+// 构建的文档可以直接作为 request.text 传给 GoTTS，自动设置 text_type=ssml
+//
+//	doc := ssml.New().
+//		Text("欢迎收听").
+//		Break(500 * time.Millisecond).
+//		Prosody(ssml.Prosody{Rate: "slow"}, func(b *ssml.Builder) {
+//			b.Text("请记下验证码").SayAs("3721", ssml.InterpretDigits, "")
+//		})
+//	params["request"]["text"] = doc
+package ssml
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Strength <break> 的停顿强度
+type Strength string
+
+const (
+	StrengthNone    Strength = "none"
+	StrengthXWeak   Strength = "x-weak"
+	StrengthWeak    Strength = "weak"
+	StrengthMedium  Strength = "medium"
+	StrengthStrong  Strength = "strong"
+	StrengthXStrong Strength = "x-strong"
+)
+
+// EmphasisLevel <emphasis> 的强调程度
+type EmphasisLevel string
+
+const (
+	EmphasisStrong   EmphasisLevel = "strong"
+	EmphasisModerate EmphasisLevel = "moderate"
+	EmphasisReduced  EmphasisLevel = "reduced"
+	EmphasisNone     EmphasisLevel = "none"
+)
+
+// InterpretAs <say-as> 的朗读方式
+type InterpretAs string
+
+const (
+	InterpretCardinal   InterpretAs = "cardinal"   // 按数值读，例如 123 读作一百二十三
+	InterpretDigits     InterpretAs = "digits"     // 逐个数字读，例如 123 读作一二三
+	InterpretTelephone  InterpretAs = "telephone"  // 电话号码
+	InterpretCharacters InterpretAs = "characters" // 逐个字符读
+	InterpretDate       InterpretAs = "date"       // 日期，format 可以是 ymd / md / ym 等
+	InterpretTime       InterpretAs = "time"       // 时间
+	InterpretCurrency   InterpretAs = "currency"   // 金额
+	InterpretAddress    InterpretAs = "address"    // 地址
+	InterpretName       InterpretAs = "name"       // 人名
+)
+
+// AlphabetPinyin <phoneme> 使用的拼音音标，声调用 1-5 表示，例如 chong2
+const AlphabetPinyin = "py"
+
+// MaxBreak <break> 停顿时长的上限
+const MaxBreak = 10 * time.Second
+
+// Prosody <prosody> 的韵律属性，至少设置一项
+// 取值为关键字或相对百分比，例如 Rate: "fast"、Pitch: "+10%"、Volume: "-20%"
+type Prosody struct {
+	Rate   string // 语速：x-slow / slow / medium / fast / x-fast / default
+	Pitch  string // 音高：x-low / low / medium / high / x-high / default
+	Volume string // 音量：silent / x-soft / soft / medium / loud / x-loud / default
+}
+
+// node 文档节点，name 为空时是文本节点
+type node struct {
+	name     string
+	attrs    []attr
+	text     string
+	children []*node
+}
+
+type attr struct {
+	name  string
+	value string
+}
+
+func (n *node) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// Builder SSML 文档构建器，方法按顺序追加内容并返回自身，可以链式调用
+// 构建过程不校验，调用 [Builder.SSML] 或 [Builder.Validate] 时统一校验
+type Builder struct {
+	root *node
+}
+
+// New 创建 <speak> 文档
+func New() *Builder {
+	return &Builder{root: &node{name: "speak"}}
+}
+
+func (b *Builder) append(n *node) *Builder {
+	b.root.children = append(b.root.children, n)
+	return b
+}
+
+func element(name string, text string, attrs ...attr) *node {
+	n := &node{name: name, attrs: attrs}
+	if text != "" {
+		n.children = []*node{{text: text}}
+	}
+	return n
+}
+
+// Text 追加文本，特殊字符会自动转义
+func (b *Builder) Text(text string) *Builder {
+	return b.append(&node{text: text})
+}
+
+// Break 追加指定时长的停顿，精确到毫秒
+func (b *Builder) Break(d time.Duration) *Builder {
+	return b.append(element("break", "", attr{"time", fmt.Sprintf("%dms", d.Milliseconds())}))
+}
+
+// BreakStrength 追加指定强度的停顿
+func (b *Builder) BreakStrength(strength Strength) *Builder {
+	return b.append(element("break", "", attr{"strength", string(strength)}))
+}
+
+// Prosody 追加调整韵律的内容，content 中向 b 追加的内容都使用该韵律
+func (b *Builder) Prosody(p Prosody, content func(b *Builder)) *Builder {
+	n := &node{name: "prosody"}
+	for _, a := range []attr{{"rate", p.Rate}, {"pitch", p.Pitch}, {"volume", p.Volume}} {
+		if a.value != "" {
+			n.attrs = append(n.attrs, a)
+		}
+	}
+	if content != nil {
+		content(&Builder{root: n})
+	}
+	return b.append(n)
+}
+
+// Emphasis 追加强调的文本
+func (b *Builder) Emphasis(level EmphasisLevel, text string) *Builder {
+	return b.append(element("emphasis", text, attr{"level", string(level)}))
+}
+
+// SayAs 追加指定朗读方式的文本，format 为空时不设置
+func (b *Builder) SayAs(text string, interpretAs InterpretAs, format string) *Builder {
+	n := element("say-as", text, attr{"interpret-as", string(interpretAs)})
+	if format != "" {
+		n.attrs = append(n.attrs, attr{"format", format})
+	}
+	return b.append(n)
+}
+
+// Phoneme 追加指定拼音读法的文本，例如 Phoneme("重", "chong2")，多个字的拼音用空格分隔
+func (b *Builder) Phoneme(text, pinyin string) *Builder {
+	return b.append(element("phoneme", text, attr{"alphabet", AlphabetPinyin}, attr{"ph", pinyin}))
+}
+
+// Sub 追加替换读法的文本，例如 Sub("WTO", "世界贸易组织")
+func (b *Builder) Sub(text, alias string) *Builder {
+	return b.append(element("sub", text, attr{"alias", alias}))
+}
+
+// Validate 按服务端支持的标签和属性校验文档
+func (b *Builder) Validate() error {
+	return validate(b.root)
+}
+
+// SSML 校验并返回文档的字符串形式
+func (b *Builder) SSML() (string, error) {
+	if err := b.Validate(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// String 返回文档的字符串形式，不做校验
+func (b *Builder) String() string {
+	var sb strings.Builder
+	render(&sb, b.root)
+	return sb.String()
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+func render(sb *strings.Builder, n *node) {
+	if n.name == "" {
+		sb.WriteString(textEscaper.Replace(n.text))
+		return
+	}
+	sb.WriteString(openTag(n))
+	if len(n.children) == 0 && n.name == "break" {
+		return
+	}
+	for _, c := range n.children {
+		render(sb, c)
+	}
+	sb.WriteString(closeTag(n))
+}
+
+// openTag 返回开始标签，<break> 没有内容，返回自闭合标签
+func openTag(n *node) string {
+	var sb strings.Builder
+	sb.WriteString("<" + n.name)
+	for _, a := range n.attrs {
+		sb.WriteString(" " + a.name + `="` + attrEscaper.Replace(a.value) + `"`)
+	}
+	if len(n.children) == 0 && n.name == "break" {
+		sb.WriteString("/>")
+	} else {
+		sb.WriteString(">")
+	}
+	return sb.String()
+}
+
+func closeTag(n *node) string {
+	return "</" + n.name + ">"
+}
@@ -0,0 +1,117 @@
+package ssml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	doc := New().
+		Text("欢迎 <收听> & ").
+		Break(500*time.Millisecond).
+		Prosody(Prosody{Rate: "slow", Volume: "+10%"}, func(b *Builder) {
+			b.Text("验证码").SayAs("3721", InterpretDigits, "")
+		}).
+		BreakStrength(StrengthStrong).
+		Phoneme("重", "chong2").
+		Sub("WTO", "世界贸易组织").
+		Emphasis(EmphasisStrong, "重要").
+		SayAs("2024-06-01", InterpretDate, "ymd")
+
+	got, err := doc.SSML()
+	if err != nil {
+		t.Fatalf("SSML() err = %v", err)
+	}
+	want := `<speak>欢迎 &lt;收听&gt; &amp; <break time="500ms"/>` +
+		`<prosody rate="slow" volume="+10%">验证码<say-as interpret-as="digits">3721</say-as></prosody>` +
+		`<break strength="strong"/><phoneme alphabet="py" ph="chong2">重</phoneme>` +
+		`<sub alias="世界贸易组织">WTO</sub><emphasis level="strong">重要</emphasis>` +
+		`<say-as interpret-as="date" format="ymd">2024-06-01</say-as></speak>`
+	if got != want {
+		t.Errorf("SSML() =\n%s\nwant\n%s", got, want)
+	}
+
+	// 解析后重新生成的文档不变
+	parsed, err := Parse(got)
+	if err != nil {
+		t.Fatalf("Parse err = %v", err)
+	}
+	if parsed.String() != got {
+		t.Errorf("round trip =\n%s", parsed.String())
+	}
+}
+
+func TestBuilderInvalid(t *testing.T) {
+	cases := map[string]*Builder{
+		"empty":            New(),
+		"only spaces":      New().Text("  "),
+		"zero break":       New().Text("a").Break(0),
+		"long break":       New().Text("a").Break(MaxBreak + time.Second),
+		"bad strength":     New().Text("a").BreakStrength("loud"),
+		"empty prosody":    New().Prosody(Prosody{}, func(b *Builder) { b.Text("a") }),
+		"bad rate":         New().Prosody(Prosody{Rate: "1.5x"}, func(b *Builder) { b.Text("a") }),
+		"bad interpret":    New().SayAs("1", "ordinal", ""),
+		"empty say-as":     New().SayAs("", InterpretDigits, ""),
+		"bad pinyin":       New().Phoneme("重", "chong"),
+		"empty alias":      New().Sub("WTO", ""),
+		"bad emphasis":     New().Emphasis("loud", "a"),
+		"nested bad child": New().Prosody(Prosody{Rate: "fast"}, func(b *Builder) { b.SayAs("1", "ordinal", "") }),
+	}
+	for name, b := range cases {
+		if _, err := b.SSML(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		`<speak>你好</speak>`,
+		`<?xml version="1.0"?><speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="zh-CN">你好</speak>`,
+		"<speak>\n  <prosody pitch=\"high\">你好<break time=\"1.5s\"/><emphasis level=\"reduced\">世界</emphasis></prosody>\n</speak>",
+		`<speak><emphasis level="strong"><sub alias="北京">京</sub></emphasis><!-- 注释 --></speak>`,
+		`<speak><phoneme ph="chong2 qing4">重庆</phoneme> &amp; <![CDATA[<raw>]]></speak>`,
+	}
+	for _, s := range valid {
+		if err := Validate(s); err != nil {
+			t.Errorf("Validate(%s) err = %v", s, err)
+		}
+	}
+
+	invalid := []string{
+		``,
+		`你好`,
+		`<speak>你好`,
+		`<speak>你好</speak>多余`,
+		`<speak>你好</speak><speak>世界</speak>`,
+		`<voice>你好</voice>`,
+		`<speak><audio src="a.mp3"/></speak>`,
+		`<speak><break time="500"/>你好</speak>`,
+		`<speak><break time="500ms">你好</break></speak>`,
+		`<speak><say-as interpret-as="digits"><break/></say-as></speak>`,
+		`<speak><say-as>123</say-as></speak>`,
+		`<speak><prosody rate="fast"><speak>你好</speak></prosody></speak>`,
+		`<speak><phoneme alphabet="ipa" ph="tʃʊŋ">重</phoneme></speak>`,
+		`<speak><sub alias="北京" lang="zh">京</sub></speak>`,
+		`<speak><x:sub alias="北京">京</x:sub></speak>`,
+		`<!DOCTYPE speak><speak>你好</speak>`,
+	}
+	for _, s := range invalid {
+		if err := Validate(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Validate(%s) err = %v, want ErrInvalid", s, err)
+		}
+	}
+}
+
+func TestParseAppend(t *testing.T) {
+	b, err := Parse(`<speak>你好</speak>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.Break(time.Second).Text("世界").SSML()
+	if err != nil || !strings.HasSuffix(got, `<break time="1000ms"/>世界</speak>`) {
+		t.Errorf("SSML() = %s, %v", got, err)
+	}
+}
@@ -0,0 +1,301 @@
+package ssml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalid 文档不符合服务端支持的 SSML 子集
+var ErrInvalid = errors.New("ssml: invalid document")
+
+// content 标签允许的内容
+type content int
+
+const (
+	contentEmpty content = iota // 没有内容，例如 <break/>
+	contentText                 // 只有非空文本，例如 <say-as>
+	contentMixed                // 文本和除 <speak> 以外的标签
+)
+
+// elementRule 标签支持的属性和内容
+type elementRule struct {
+	attrs    map[string]func(string) error
+	required []string
+	content  content
+}
+
+var rules map[string]elementRule
+
+func init() {
+	rules = map[string]elementRule{
+		"speak": {
+			attrs:   map[string]func(string) error{"version": anyValue, "xmlns": anyValue, "xml:lang": anyValue},
+			content: contentMixed,
+		},
+		"break": {
+			attrs: map[string]func(string) error{
+				"time":     checkBreakTime,
+				"strength": oneOf(StrengthNone, StrengthXWeak, StrengthWeak, StrengthMedium, StrengthStrong, StrengthXStrong),
+			},
+			content: contentEmpty,
+		},
+		"prosody": {
+			attrs: map[string]func(string) error{
+				"rate":   relativeOr("x-slow", "slow", "medium", "fast", "x-fast", "default"),
+				"pitch":  relativeOr("x-low", "low", "medium", "high", "x-high", "default"),
+				"volume": relativeOr("silent", "x-soft", "soft", "medium", "loud", "x-loud", "default"),
+			},
+			content: contentMixed,
+		},
+		"emphasis": {
+			attrs:   map[string]func(string) error{"level": oneOf(EmphasisStrong, EmphasisModerate, EmphasisReduced, EmphasisNone)},
+			content: contentMixed,
+		},
+		"say-as": {
+			attrs: map[string]func(string) error{
+				"interpret-as": oneOf(InterpretCardinal, InterpretDigits, InterpretTelephone, InterpretCharacters,
+					InterpretDate, InterpretTime, InterpretCurrency, InterpretAddress, InterpretName),
+				"format": anyValue,
+			},
+			required: []string{"interpret-as"},
+			content:  contentText,
+		},
+		"phoneme": {
+			attrs:    map[string]func(string) error{"alphabet": oneOf(AlphabetPinyin), "ph": checkPinyin},
+			required: []string{"ph"},
+			content:  contentText,
+		},
+		"sub": {
+			attrs:    map[string]func(string) error{"alias": notEmpty},
+			required: []string{"alias"},
+			content:  contentText,
+		},
+	}
+}
+
+func anyValue(string) error { return nil }
+
+func notEmpty(v string) error {
+	if strings.TrimSpace(v) == "" {
+		return errors.New("cannot be empty")
+	}
+	return nil
+}
+
+func oneOf[T ~string](values ...T) func(string) error {
+	return func(v string) error {
+		for _, allowed := range values {
+			if v == string(allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not supported", v)
+	}
+}
+
+var relativeValue = regexp.MustCompile(`^[+-]?\d+(\.\d+)?%$`)
+
+// relativeOr 关键字或相对百分比，例如 +10%
+func relativeOr(keywords ...string) func(string) error {
+	keyword := oneOf(keywords...)
+	return func(v string) error {
+		if relativeValue.MatchString(v) {
+			return nil
+		}
+		if keyword(v) != nil {
+			return fmt.Errorf("%q is not supported, use one of %s or a percentage", v, strings.Join(keywords, " / "))
+		}
+		return nil
+	}
+}
+
+// checkBreakTime 停顿时长，例如 500ms、1.5s，不超过 MaxBreak
+func checkBreakTime(v string) error {
+	if !strings.HasSuffix(v, "ms") && !strings.HasSuffix(v, "s") {
+		return fmt.Errorf("%q should end with ms or s", v)
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration", v)
+	}
+	if d <= 0 || d > MaxBreak {
+		return fmt.Errorf("%s out of range (0, %s]", v, MaxBreak)
+	}
+	return nil
+}
+
+var pinyinSyllables = regexp.MustCompile(`^[a-zü]+[1-5]( +[a-zü]+[1-5])*$`)
+
+// checkPinyin 空格分隔的带声调拼音，例如 chong2 qing4
+func checkPinyin(v string) error {
+	if !pinyinSyllables.MatchString(strings.TrimSpace(v)) {
+		return fmt.Errorf("%q is not pinyin with tones, e.g. chong2", v)
+	}
+	return nil
+}
+
+// validate 校验以 n 为根的文档，根节点必须是 <speak>
+func validate(n *node) error {
+	if n.name != "speak" {
+		return fmt.Errorf("%w: root element must be <speak>", ErrInvalid)
+	}
+	if !hasContent(n) {
+		return fmt.Errorf("%w: document has no text", ErrInvalid)
+	}
+	return validateElement(n)
+}
+
+func validateElement(n *node) error {
+	rule, ok := rules[n.name]
+	if !ok {
+		return fmt.Errorf("%w: element <%s> is not supported", ErrInvalid, n.name)
+	}
+	for _, a := range n.attrs {
+		check, ok := rule.attrs[a.name]
+		if !ok {
+			return fmt.Errorf("%w: attribute %s of <%s> is not supported", ErrInvalid, a.name, n.name)
+		}
+		if err := check(a.value); err != nil {
+			return fmt.Errorf("%w: <%s %s>: %v", ErrInvalid, n.name, a.name, err)
+		}
+	}
+	for _, name := range rule.required {
+		if _, ok := n.attr(name); !ok {
+			return fmt.Errorf("%w: <%s> requires attribute %s", ErrInvalid, n.name, name)
+		}
+	}
+	if n.name == "prosody" && len(n.attrs) == 0 {
+		return fmt.Errorf("%w: <prosody> requires rate, pitch or volume", ErrInvalid)
+	}
+
+	switch rule.content {
+	case contentEmpty:
+		if len(n.children) > 0 {
+			return fmt.Errorf("%w: <%s> must be empty", ErrInvalid, n.name)
+		}
+	case contentText:
+		for _, c := range n.children {
+			if c.name != "" {
+				return fmt.Errorf("%w: <%s> can only contain text, found <%s>", ErrInvalid, n.name, c.name)
+			}
+		}
+		if !hasContent(n) {
+			return fmt.Errorf("%w: <%s> cannot be empty", ErrInvalid, n.name)
+		}
+	case contentMixed:
+		for _, c := range n.children {
+			if c.name == "" {
+				continue
+			}
+			if c.name == "speak" {
+				return fmt.Errorf("%w: <speak> cannot be nested", ErrInvalid)
+			}
+			if err := validateElement(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasContent 节点中是否有非空白文本或停顿
+func hasContent(n *node) bool {
+	if n.name == "" {
+		return strings.TrimSpace(n.text) != ""
+	}
+	if n.name == "break" {
+		return true
+	}
+	for _, c := range n.children {
+		if hasContent(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse 解析 SSML 文档并校验，返回的 Builder 可以继续追加内容
+func Parse(s string) (*Builder, error) {
+	d := xml.NewDecoder(strings.NewReader(s))
+	var root *node
+	var stack []*node
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != "" {
+				return nil, fmt.Errorf("%w: element <%s:%s> is not supported", ErrInvalid, t.Name.Space, t.Name.Local)
+			}
+			if root != nil && len(stack) == 0 {
+				return nil, fmt.Errorf("%w: content after </speak>", ErrInvalid)
+			}
+			n := &node{name: t.Name.Local}
+			for _, a := range t.Attr {
+				name := a.Name.Local
+				if a.Name.Space != "" {
+					name = a.Name.Space + ":" + name
+				}
+				n.attrs = append(n.attrs, attr{name, a.Value})
+			}
+			if len(stack) == 0 {
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != t.Name.Local || t.Name.Space != "" {
+				return nil, fmt.Errorf("%w: unexpected </%s>", ErrInvalid, t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				if strings.TrimSpace(string(t)) != "" {
+					return nil, fmt.Errorf("%w: text outside <speak>", ErrInvalid)
+				}
+				continue
+			}
+			parent := stack[len(stack)-1]
+			if k := len(parent.children); k > 0 && parent.children[k-1].name == "" {
+				// 实体和 CDATA 会拆出多个文本片段，合并为一个文本节点
+				parent.children[k-1].text += string(t)
+			} else {
+				parent.children = append(parent.children, &node{text: string(t)})
+			}
+		case xml.ProcInst:
+			if t.Target != "xml" || root != nil {
+				return nil, fmt.Errorf("%w: processing instruction <?%s?> is not supported", ErrInvalid, t.Target)
+			}
+		case xml.Directive:
+			return nil, fmt.Errorf("%w: directive <!%s> is not supported", ErrInvalid, t)
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: missing <speak>", ErrInvalid)
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: <%s> is not closed", ErrInvalid, stack[len(stack)-1].name)
+	}
+	if err := validate(root); err != nil {
+		return nil, err
+	}
+	return &Builder{root: root}, nil
+}
+
+// Validate 校验 SSML 文档是否符合服务端支持的标签、属性和嵌套规则
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
@@ -0,0 +1,108 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+	"github.com/zmexing/go-byte-tts/ssml"
+)
+
+func newSSMLParams(text any) map[string]map[string]any {
+	return map[string]map[string]any{
+		"user":    {"uid": "uid"},
+		"audio":   {"voice_type": "BV406_V2_streaming", "encoding": "pcm"},
+		"request": {"reqid": "reqid", "text": text, "operation": "query"},
+	}
+}
+
+func TestTextToVoiceSSMLDocument(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	doc := ssml.New().Text("你好").Break(200*time.Millisecond).Sub("WTO", "世界贸易组织")
+	_, closeFn, err := tts.TextToVoiceContext(context.Background(), newSSMLParams(doc))
+	if err != nil {
+		t.Fatalf("TextToVoiceContext err = %v", err)
+	}
+	closeFn()
+
+	var body struct {
+		Request struct {
+			Text     string `json:"text"`
+			TextType string `json:"text_type"`
+		} `json:"request"`
+	}
+	if err := srv.RequestsTo(bytettstest.PathTTS)[0].JSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Request.TextType != "ssml" || body.Request.Text != doc.String() {
+		t.Errorf("request = %+v", body.Request)
+	}
+
+	// 无效的 SSML 在发送前返回错误
+	invalid := []map[string]map[string]any{
+		newSSMLParams(ssml.New().SayAs("1", "ordinal", "")),
+		newSSMLParams("<speak>你好"),
+	}
+	invalid[1]["request"]["text_type"] = "ssml"
+	for _, params := range invalid {
+		if _, _, err := tts.TextToVoiceContext(context.Background(), params); !errors.Is(err, ssml.ErrInvalid) {
+			t.Errorf("err = %v, want ssml.ErrInvalid", err)
+		}
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("requests = %d, invalid SSML should not be sent", n)
+	}
+
+	req := newTestRequest()
+	req.Request.TextType = "ssml"
+	if err := req.Validate(); !errors.Is(err, ssml.ErrInvalid) {
+		t.Errorf("Validate() err = %v, want ssml.ErrInvalid", err)
+	}
+}
+
+func TestTextToJoinVoiceSSML(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	// 逐个分片合成，请求顺序与分片顺序一致
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithMaxConcurrency(1))
+
+	doc := ssml.New().Prosody(ssml.Prosody{Rate: "slow"}, func(b *ssml.Builder) {
+		b.Text(strings.Repeat("这是一段很长的文本。", 60)).
+			Phoneme("重", "chong2").
+			Text(strings.Repeat("第二部分的文本。", 60))
+	})
+	var buf bytes.Buffer
+	if err := tts.TextToJoinVoiceWriter(context.Background(), newSSMLParams(doc), &buf); err != nil {
+		t.Fatalf("TextToJoinVoiceWriter err = %v", err)
+	}
+
+	reqs := srv.RequestsTo(bytettstest.PathTTS)
+	if len(reqs) < 2 {
+		t.Fatalf("requests = %d, want the document split", len(reqs))
+	}
+	// 模拟服务返回请求文本作为音频，拼接结果即为按顺序的全部分片
+	var joined strings.Builder
+	for i, r := range reqs {
+		text := r.Text()
+		if len(text) > maxTextBytes {
+			t.Errorf("chunk %d has %d bytes", i, len(text))
+		}
+		if err := ssml.Validate(text); err != nil {
+			t.Errorf("chunk %d: %v", i, err)
+		}
+		if !strings.HasPrefix(text, `<speak><prosody rate="slow">`) {
+			t.Errorf("chunk %d = %s", i, text)
+		}
+		joined.WriteString(text)
+	}
+	if buf.String() != joined.String() {
+		t.Error("joined audio does not match the chunks in order")
+	}
+}
@@ -0,0 +1,364 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+// 流式合成二进制协议的消息类型，位于头部第2个字节的高4位
+const (
+	streamFullClientRequest = 0x1
+	streamAudioOnlyResponse = 0xB
+	streamFrontendResponse  = 0xC
+	streamErrorMessage      = 0xF
+)
+
+const (
+	// 音频消息的标志为0时不带序号，是服务端的确认消息
+	streamFlagNoSequence = 0x0
+	// 压缩方式，位于头部第3个字节的低4位
+	streamCompressionGzip = 0x1
+	// 流式合成的 operation
+	streamOperationSubmit = "submit"
+	// 解压后的最大长度
+	streamMaxDecompressedSize = 16 << 20
+)
+
+// streamRequestHeader 协议版本1、头部长度4字节、完整客户端请求、JSON 序列化、gzip 压缩
+var streamRequestHeader = []byte{0x11, streamFullClientRequest << 4, 0x11, 0x00}
+
+// AudioChunk 流式合成返回的一段音频
+type AudioChunk struct {
+	Audio    []byte // 音频数据，格式与请求的 audio.encoding 一致
+	Sequence int    // 服务端返回的分片序号，最后一个分片为负数
+	Last     bool   // 是否为最后一个分片
+}
+
+// AudioStream 流式合成的音频流，通过 Recv 按顺序读取音频分片
+// ctx 取消后连接会被关闭，Recv 返回 ctx 的错误
+type AudioStream struct {
+	ctx      context.Context
+	conn     *internal.WsConn
+	reqID    string
+	encoding string
+
+	frontend string
+	err      error
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// StreamSynthesize 通过 WebSocket 二进制协议流式合成，operation 固定为 submit
+// 握手失败时按重试策略重试，开始接收音频后不会重试
+func (g *GoTTS) StreamSynthesize(ctx context.Context, req *SynthesisRequest) (*AudioStream, error) {
+	params, err := req.Params()
+	if err == nil {
+		err = g.checkParamsVoice(params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	params["request"]["operation"] = streamOperationSubmit
+	params["app"] = map[string]any{
+		"appid":   g.appId,
+		"token":   "access_token",
+		"cluster": g.cluster,
+	}
+
+	var conn *internal.WsConn
+	err = g.retry(ctx, func(attempt int) error {
+		if attempt > 0 {
+			// 每次重试使用新的请求标识
+			params = internal.DeepCopyParams(params)
+			params["request"]["reqid"] = uuid.NewString()
+		}
+		var err error
+		conn, err = g.dialStream(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoding := req.Audio.Encoding
+	if encoding == "" {
+		encoding = defaultEncoding
+	}
+	s := &AudioStream{
+		ctx:      ctx,
+		conn:     conn,
+		reqID:    params["request"]["reqid"].(string),
+		encoding: encoding,
+		closed:   make(chan struct{}),
+	}
+	// 握手完成后连接不再受请求的 ctx 控制，需要在 ctx 取消时主动关闭
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.closed:
+		}
+	}()
+	return s, nil
+}
+
+// dialStream 建立 WebSocket 连接并发送合成请求
+func (g *GoTTS) dialStream(ctx context.Context, params map[string]map[string]any) (*internal.WsConn, error) {
+	if err := g.waitRateLimit(ctx, g.limiters.synthesis); err != nil {
+		return nil, err
+	}
+
+	payload, err := encodeStreamRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", fmt.Sprintf("Bearer;%s", g.token))
+	conn, err := internal.DialWebSocket(ctx, g.httpClient, g.endpoints.TtsWs, header)
+	if err != nil {
+		var hsErr *internal.WsHandshakeError
+		if errors.As(err, &hsErr) {
+			return nil, handshakeAPIError(hsErr, params)
+		}
+		return nil, err
+	}
+
+	if err := conn.WriteMessage(internal.WsBinary, payload); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("send stream request error: %w", err)
+	}
+	return conn, nil
+}
+
+// handshakeAPIError 握手失败时服务端可能返回与短文本接口相同格式的 JSON
+func handshakeAPIError(hsErr *internal.WsHandshakeError, params map[string]map[string]any) error {
+	var rep Rep
+	_ = json.Unmarshal(hsErr.Body, &rep)
+	message := rep.Message
+	if message == "" {
+		message = string(hsErr.Body)
+	}
+	reqid, _ := params["request"]["reqid"].(string)
+	return newAPIError(hsErr.StatusCode, rep.Code, message, reqid)
+}
+
+// Recv 返回下一个音频分片，最后一个分片之后返回 io.EOF
+// 服务端返回错误消息时返回 *APIError
+func (s *AudioStream) Recv() (*AudioChunk, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for {
+		_, message, err := s.conn.ReadMessage()
+		if err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return nil, s.fail(ctxErr)
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, s.fail(fmt.Errorf("read stream error: %w", err))
+		}
+
+		resp, err := decodeStreamResponse(message)
+		if err != nil {
+			return nil, s.fail(err)
+		}
+
+		switch resp.messageType {
+		case streamAudioOnlyResponse:
+			// 不带序号的消息是服务端对请求的确认
+			if resp.flags == streamFlagNoSequence {
+				continue
+			}
+			chunk := &AudioChunk{
+				Audio:    resp.payload,
+				Sequence: int(resp.sequence),
+				Last:     resp.sequence < 0,
+			}
+			if chunk.Last {
+				s.fail(io.EOF)
+			}
+			return chunk, nil
+		case streamFrontendResponse:
+			s.frontend = string(resp.payload)
+		case streamErrorMessage:
+			return nil, s.fail(newAPIError(http.StatusOK, resp.code, string(resp.payload), s.reqID))
+		default:
+			return nil, s.fail(fmt.Errorf("unexpected stream message type %#x", resp.messageType))
+		}
+	}
+}
+
+// fail 记录结束原因并关闭连接，之后的 Recv 都返回该错误
+func (s *AudioStream) fail(err error) error {
+	s.err = err
+	s.Close()
+	return err
+}
+
+// WriteTo 将剩余的音频全部写入 w
+func (s *AudioStream) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for {
+		chunk, err := s.Recv()
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(chunk.Audio)
+		written += int64(n)
+		if err != nil {
+			s.Close()
+			return written, err
+		}
+	}
+}
+
+// ReqID 请求标识，重试时为最后一次请求的标识
+func (s *AudioStream) ReqID() string {
+	return s.reqID
+}
+
+// Encoding 音频编码
+func (s *AudioStream) Encoding() string {
+	return s.encoding
+}
+
+// Frontend 服务端返回的前端信息（JSON字符串），请求设置 with_frontend 时在音频之前返回
+func (s *AudioStream) Frontend() string {
+	return s.frontend
+}
+
+// Close 关闭连接，可以重复调用
+func (s *AudioStream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// encodeStreamRequest 编码客户端请求：4字节头部 + 4字节长度 + gzip 压缩的 JSON
+func encodeStreamRequest(params map[string]map[string]any) ([]byte, error) {
+	jsonStr, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload bytes.Buffer
+	zw := gzip.NewWriter(&payload)
+	if _, err := zw.Write(jsonStr); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, len(streamRequestHeader)+4, len(streamRequestHeader)+4+payload.Len())
+	copy(b, streamRequestHeader)
+	binary.BigEndian.PutUint32(b[len(streamRequestHeader):], uint32(payload.Len()))
+	return append(b, payload.Bytes()...), nil
+}
+
+// streamResponse 解码后的服务端消息
+type streamResponse struct {
+	messageType byte
+	flags       byte
+	sequence    int32  // 音频消息的序号
+	code        int    // 错误消息的错误码
+	payload     []byte // 音频、前端信息或错误信息
+}
+
+// decodeStreamResponse 解码服务端消息
+// 头部为 版本(4bit) 头部长度(4bit) 消息类型(4bit) 标志(4bit) 序列化方式(4bit) 压缩方式(4bit) 保留(8bit)
+func decodeStreamResponse(b []byte) (*streamResponse, error) {
+	if len(b) < 4 {
+		return nil, errors.New("invalid stream message: header too short")
+	}
+	headerSize := int(b[0]&0x0F) * 4
+	if headerSize < 4 || len(b) < headerSize {
+		return nil, errors.New("invalid stream message: bad header size")
+	}
+	resp := &streamResponse{
+		messageType: b[1] >> 4,
+		flags:       b[1] & 0x0F,
+	}
+	compression := b[2] & 0x0F
+	p := b[headerSize:]
+
+	var err error
+	switch resp.messageType {
+	case streamAudioOnlyResponse:
+		if resp.flags == streamFlagNoSequence {
+			return resp, nil
+		}
+		if len(p) < 8 {
+			return nil, errors.New("invalid stream message: audio header too short")
+		}
+		resp.sequence = int32(binary.BigEndian.Uint32(p[0:4]))
+		resp.payload, err = streamPayload(p[4:])
+		if err == nil && compression == streamCompressionGzip {
+			resp.payload, err = gunzip(resp.payload)
+		}
+	case streamFrontendResponse:
+		resp.payload, err = streamPayload(p)
+		if err == nil && compression == streamCompressionGzip {
+			resp.payload, err = gunzip(resp.payload)
+		}
+	case streamErrorMessage:
+		if len(p) < 8 {
+			return nil, errors.New("invalid stream message: error header too short")
+		}
+		resp.code = int(binary.BigEndian.Uint32(p[0:4]))
+		resp.payload, err = streamPayload(p[4:])
+		if err == nil && compression == streamCompressionGzip {
+			resp.payload, err = gunzip(resp.payload)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// streamPayload 读取 4字节长度 + 内容
+func streamPayload(p []byte) ([]byte, error) {
+	if len(p) < 4 {
+		return nil, errors.New("invalid stream message: payload size missing")
+	}
+	size := binary.BigEndian.Uint32(p[0:4])
+	if uint64(size) > uint64(len(p)-4) {
+		return nil, errors.New("invalid stream message: truncated payload")
+	}
+	return p[4 : 4+size], nil
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("gzip decode error: %w", err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, streamMaxDecompressedSize))
+	if err != nil {
+		return nil, fmt.Errorf("gzip decode error: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,272 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// streamMessage 按二进制协议编码服务端消息，fields 依次写入 4 字节的大端整数
+func streamMessage(msgType, flags, compression byte, payload []byte, fields ...uint32) []byte {
+	b := []byte{0x11, msgType<<4 | flags, 0x10 | compression, 0x00}
+	for _, f := range fields {
+		b = append(b, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], f)
+	}
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], uint32(len(payload)))
+	return append(b, payload...)
+}
+
+func audioMessage(seq int32, audio string) []byte {
+	return streamMessage(streamAudioOnlyResponse, 1, 0, []byte(audio), uint32(seq))
+}
+
+// newStreamServer 本地模拟的流式合成服务，handle 收到解码后的请求参数后发送响应
+func newStreamServer(t *testing.T, handle func(conn *internal.WsConn, params map[string]map[string]any)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != apiTtsWs {
+			http.NotFound(w, r)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer;token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(Rep{Code: 3001, Message: "invalid token"})
+			return
+		}
+		conn, err := internal.AcceptWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Errorf("read request err = %v", err)
+			return
+		}
+		if !bytes.Equal(message[:4], streamRequestHeader) {
+			t.Errorf("request header = %x", message[:4])
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(message[8:]))
+		if err != nil {
+			t.Errorf("gzip err = %v", err)
+			return
+		}
+		var params map[string]map[string]any
+		if err := json.NewDecoder(zr).Decode(&params); err != nil {
+			t.Errorf("decode request err = %v", err)
+			return
+		}
+		handle(conn, params)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStreamSynthesize(t *testing.T) {
+	srv := newStreamServer(t, func(conn *internal.WsConn, params map[string]map[string]any) {
+		if op := params["request"]["operation"]; op != "submit" {
+			t.Errorf("operation = %v, want submit", op)
+		}
+		if appid := params["app"]["appid"]; appid != "appid" {
+			t.Errorf("appid = %v", appid)
+		}
+		frontend := gzipBytes(t, []byte(`{"words":[]}`))
+		for _, m := range [][]byte{
+			streamMessage(streamAudioOnlyResponse, 0, 0, nil)[:4],
+			streamMessage(streamFrontendResponse, 0, streamCompressionGzip, frontend),
+			audioMessage(1, "aa"),
+			audioMessage(2, "bb"),
+			audioMessage(-3, "cc"),
+		} {
+			if err := conn.WriteMessage(internal.WsBinary, m); err != nil {
+				t.Errorf("write err = %v", err)
+				return
+			}
+		}
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	stream, err := tts.StreamSynthesize(context.Background(), newTestRequest())
+	if err != nil {
+		t.Fatalf("StreamSynthesize err = %v", err)
+	}
+	defer stream.Close()
+
+	var audio []byte
+	var sequences []int
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv err = %v", err)
+		}
+		audio = append(audio, chunk.Audio...)
+		sequences = append(sequences, chunk.Sequence)
+		if chunk.Last != (chunk.Sequence < 0) {
+			t.Errorf("chunk %d Last = %v", chunk.Sequence, chunk.Last)
+		}
+	}
+	if string(audio) != "aabbcc" {
+		t.Errorf("audio = %q, want aabbcc", audio)
+	}
+	if len(sequences) != 3 || sequences[2] != -3 {
+		t.Errorf("sequences = %v", sequences)
+	}
+	if stream.Frontend() != `{"words":[]}` {
+		t.Errorf("Frontend = %q", stream.Frontend())
+	}
+	if stream.Encoding() != "mp3" || stream.ReqID() == "" {
+		t.Errorf("Encoding = %q, ReqID = %q", stream.Encoding(), stream.ReqID())
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv after last = %v, want io.EOF", err)
+	}
+}
+
+func TestStreamSynthesizeWriteTo(t *testing.T) {
+	srv := newStreamServer(t, func(conn *internal.WsConn, params map[string]map[string]any) {
+		_ = conn.WriteMessage(internal.WsBinary, audioMessage(1, "hello "))
+		_ = conn.WriteMessage(internal.WsBinary, audioMessage(-2, "world"))
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	stream, err := tts.StreamSynthesize(context.Background(), newTestRequest())
+	if err != nil {
+		t.Fatalf("StreamSynthesize err = %v", err)
+	}
+	defer stream.Close()
+	var buf bytes.Buffer
+	if n, err := stream.WriteTo(&buf); err != nil || n != 11 {
+		t.Fatalf("WriteTo = %d, %v", n, err)
+	}
+	if buf.String() != "hello world" {
+		t.Errorf("audio = %q", buf.String())
+	}
+}
+
+func TestStreamSynthesizeGzipAudio(t *testing.T) {
+	srv := newStreamServer(t, func(conn *internal.WsConn, params map[string]map[string]any) {
+		_ = conn.WriteMessage(internal.WsBinary, audioMessage(1, "plain "))
+		last := int32(-2)
+		audio := gzipBytes(t, []byte("gzipped"))
+		_ = conn.WriteMessage(internal.WsBinary, streamMessage(streamAudioOnlyResponse, 1, streamCompressionGzip, audio, uint32(last)))
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	stream, err := tts.StreamSynthesize(context.Background(), newTestRequest())
+	if err != nil {
+		t.Fatalf("StreamSynthesize err = %v", err)
+	}
+	defer stream.Close()
+	var buf bytes.Buffer
+	if _, err := stream.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo err = %v", err)
+	}
+	if buf.String() != "plain gzipped" {
+		t.Errorf("audio = %q, want decompressed audio", buf.String())
+	}
+}
+
+func TestStreamSynthesizeErrorMessage(t *testing.T) {
+	srv := newStreamServer(t, func(conn *internal.WsConn, params map[string]map[string]any) {
+		_ = conn.WriteMessage(internal.WsBinary, audioMessage(1, "aa"))
+		msg := gzipBytes(t, []byte("text too long"))
+		_ = conn.WriteMessage(internal.WsBinary, streamMessage(streamErrorMessage, 0, streamCompressionGzip, msg, 3010))
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	stream, err := tts.StreamSynthesize(context.Background(), newTestRequest())
+	if err != nil {
+		t.Fatalf("StreamSynthesize err = %v", err)
+	}
+	defer stream.Close()
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("first Recv err = %v", err)
+	}
+	_, err = stream.Recv()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 3010 || apiErr.Message != "text too long" {
+		t.Fatalf("err = %v, want APIError 3010", err)
+	}
+	if !errors.Is(err, ErrInvalidText) {
+		t.Errorf("err = %v, want ErrInvalidText", err)
+	}
+}
+
+func TestStreamSynthesizeHandshakeError(t *testing.T) {
+	srv := newStreamServer(t, func(conn *internal.WsConn, params map[string]map[string]any) {})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithToken("bad"))
+
+	_, err := tts.StreamSynthesize(context.Background(), newTestRequest())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusUnauthorized || apiErr.Code != 3001 {
+		t.Fatalf("err = %v, want 401 APIError", err)
+	}
+}
+
+func TestStreamSynthesizeContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv := newStreamServer(t, func(conn *internal.WsConn, params map[string]map[string]any) {
+		_ = conn.WriteMessage(internal.WsBinary, audioMessage(1, "aa"))
+		<-release
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := tts.StreamSynthesize(ctx, newTestRequest())
+	if err != nil {
+		t.Fatalf("StreamSynthesize err = %v", err)
+	}
+	defer stream.Close()
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("first Recv err = %v", err)
+	}
+
+	time.AfterFunc(time.Millisecond*50, cancel)
+	if _, err := stream.Recv(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+}
+
+func TestNewEndpointsWebSocket(t *testing.T) {
+	tests := map[string]string{
+		"https://openspeech.bytedance.com": "wss://openspeech.bytedance.com" + apiTtsWs,
+		"http://127.0.0.1:8080/":           "ws://127.0.0.1:8080" + apiTtsWs,
+	}
+	for base, want := range tests {
+		if got := NewEndpoints(base).TtsWs; got != want {
+			t.Errorf("NewEndpoints(%q).TtsWs = %q, want %q", base, got, want)
+		}
+	}
+	if !strings.HasPrefix(NewEndpoints(DefaultBaseURL).TtsWs, "wss://") {
+		t.Error("default stream endpoint should use wss")
+	}
+}
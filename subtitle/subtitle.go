@@ -0,0 +1,176 @@
+// Package subtitle 根据语音合成返回的字词时间戳生成字幕
+//
+// 字词按停顿、句末标点、时长和行宽合并为字幕条目，可以导出为 SRT、WebVTT 或 JSON 时间轴
+//
+//	cues := subtitle.Cues(timeline.Words, subtitle.Options{MaxLineLength: 16})
+//	err := subtitle.WriteSRT(f, cues)
+package subtitle
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Word 单个字词及其在音频中的起止时间
+type Word struct {
+	Text  string
+	Start time.Duration
+	End   time.Duration
+}
+
+// Cue 一条字幕，Lines 为按行宽折行后的文本
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Text 字幕的文本，多行之间用换行连接
+func (c Cue) Text() string {
+	return strings.Join(c.Lines, "\n")
+}
+
+// Options 字词合并为字幕的规则，零值使用默认值
+type Options struct {
+	MaxLineLength int           // 每行最多的字符数，默认为 20
+	MaxLines      int           // 每条字幕最多的行数，默认为 2
+	MaxDuration   time.Duration // 每条字幕最长的显示时间，默认为 7 秒
+	MaxGap        time.Duration // 字词之间的停顿超过该时长时开始新的字幕，默认为 800 毫秒
+	// KeepSentences 为 false 时，句末标点（。！？.!? 等）之后总是开始新的字幕
+	// 为 true 时，短句可以合并到同一条字幕中
+	KeepSentences bool
+}
+
+const (
+	defaultMaxLineLength = 20
+	defaultMaxLines      = 2
+	defaultMaxDuration   = 7 * time.Second
+	defaultMaxGap        = 800 * time.Millisecond
+)
+
+func (o Options) withDefaults() Options {
+	if o.MaxLineLength <= 0 {
+		o.MaxLineLength = defaultMaxLineLength
+	}
+	if o.MaxLines <= 0 {
+		o.MaxLines = defaultMaxLines
+	}
+	if o.MaxDuration <= 0 {
+		o.MaxDuration = defaultMaxDuration
+	}
+	if o.MaxGap <= 0 {
+		o.MaxGap = defaultMaxGap
+	}
+	return o
+}
+
+// Cues 按 opts 的规则将字词合并为字幕，空白的字词会被忽略
+// 标点不会出现在行首，英文单词之间自动添加空格
+func Cues(words []Word, opts Options) []Cue {
+	opts = opts.withDefaults()
+
+	var cues []Cue
+	var current []Word
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		cues = append(cues, Cue{
+			Start: current[0].Start,
+			End:   current[len(current)-1].End,
+			Lines: wrap(current, opts.MaxLineLength),
+		})
+		current = nil
+	}
+
+	for _, w := range words {
+		w.Text = strings.TrimSpace(w.Text)
+		if w.Text == "" {
+			continue
+		}
+		if len(current) > 0 && !isPunct(w.Text) {
+			last := current[len(current)-1]
+			switch {
+			case w.Start-last.End > opts.MaxGap:
+				flush()
+			case !opts.KeepSentences && endsSentence(last.Text):
+				flush()
+			case w.End-current[0].Start > opts.MaxDuration:
+				flush()
+			case len(wrap(append(current[:len(current):len(current)], w), opts.MaxLineLength)) > opts.MaxLines:
+				flush()
+			}
+		}
+		current = append(current, w)
+	}
+	flush()
+	return cues
+}
+
+// wrap 按行宽折行，优先在字词之间换行，超过一行的字词按字符切开
+func wrap(words []Word, maxLen int) []string {
+	var lines []string
+	var line strings.Builder
+	lineLen := 0
+	for i, w := range words {
+		text := w.Text
+		if i > 0 && needSpace(words[i-1].Text, text) {
+			text = " " + text
+		}
+		n := utf8.RuneCountInString(text)
+		switch {
+		case isPunct(w.Text) || lineLen+n <= maxLen:
+			// 标点总是跟在前一个字词后面
+		case lineLen > 0:
+			lines = append(lines, line.String())
+			line.Reset()
+			text = strings.TrimLeft(text, " ")
+			n = utf8.RuneCountInString(text)
+			lineLen = 0
+		}
+		for n > maxLen && lineLen == 0 {
+			head := string([]rune(text)[:maxLen])
+			lines = append(lines, head)
+			text = text[len(head):]
+			n -= maxLen
+		}
+		line.WriteString(text)
+		lineLen += n
+	}
+	if lineLen > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// needSpace 英文、数字单词与前面的英文单词或英文标点之间需要空格
+func needSpace(prev, next string) bool {
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(next)
+	return isWordRune(first) && (isWordRune(last) || strings.ContainsRune(",.;:!?)", last))
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// isPunct 只包含标点的字词
+func isPunct(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPunct(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func endsSentence(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	switch r {
+	case '。', '！', '？', '…', '.', '!', '?':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package subtitle
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// words 每个字词 100 毫秒，依次排列
+func words(texts ...string) []Word {
+	var out []Word
+	var at time.Duration
+	for _, t := range texts {
+		out = append(out, Word{Text: t, Start: at, End: at + 100*time.Millisecond})
+		at += 100 * time.Millisecond
+	}
+	return out
+}
+
+func cueTexts(cues []Cue) []string {
+	var out []string
+	for _, c := range cues {
+		out = append(out, c.Text())
+	}
+	return out
+}
+
+func TestCuesSentences(t *testing.T) {
+	ws := words("你", "好", "。", "欢", "迎", "！", "Hello", ",", "world", ".")
+	got := cueTexts(Cues(ws, Options{}))
+	want := []string{"你好。", "欢迎！", "Hello, world."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cues = %q, want %q", got, want)
+	}
+
+	got = cueTexts(Cues(ws, Options{KeepSentences: true}))
+	want = []string{"你好。欢迎！Hello, world."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeepSentences cues = %q, want %q", got, want)
+	}
+}
+
+func TestCuesGapAndDuration(t *testing.T) {
+	ws := words("一", "二", "三", "四")
+	// 三之前停顿 1 秒
+	ws[2].Start += time.Second
+	ws[2].End += time.Second
+	ws[3].Start += time.Second
+	ws[3].End += time.Second
+	cues := Cues(ws, Options{})
+	if got := cueTexts(cues); !reflect.DeepEqual(got, []string{"一二", "三四"}) {
+		t.Errorf("cues = %q", got)
+	}
+	if cues[1].Start != 1200*time.Millisecond || cues[1].End != 1400*time.Millisecond {
+		t.Errorf("cue = %+v", cues[1])
+	}
+
+	cues = Cues(words("一", "二", "三", "四", "五"), Options{MaxDuration: 250 * time.Millisecond})
+	if got := cueTexts(cues); !reflect.DeepEqual(got, []string{"一二", "三四", "五"}) {
+		t.Errorf("MaxDuration cues = %q", got)
+	}
+}
+
+func TestCuesLines(t *testing.T) {
+	ws := words("今天", "天气", "很好", "，", "适合", "出门", "散步", "Let", "go")
+	cues := Cues(ws, Options{MaxLineLength: 4, MaxLines: 2})
+	var got [][]string
+	for _, c := range cues {
+		got = append(got, c.Lines)
+	}
+	want := [][]string{{"今天天气", "很好，"}, {"适合出门", "散步"}, {"Let", "go"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+
+	// 超过一行的字词按字符切开
+	cues = Cues(words("supercalifragilistic"), Options{MaxLineLength: 8, MaxLines: 3})
+	if len(cues) != 1 || !reflect.DeepEqual(cues[0].Lines, []string{"supercal", "ifragili", "stic"}) {
+		t.Errorf("cues = %+v", cues)
+	}
+
+	if cues := Cues(words(" ", ""), Options{}); len(cues) != 0 {
+		t.Errorf("blank words should be ignored, cues = %+v", cues)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 1500 * time.Millisecond, Lines: []string{"你好", "世界"}},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Lines: []string{"再见"}},
+	}
+
+	var srt bytes.Buffer
+	if err := WriteSRT(&srt, cues); err != nil {
+		t.Fatal(err)
+	}
+	wantSRT := "1\n00:00:00,000 --> 00:00:01,500\n你好\n世界\n\n2\n01:02:03,004 --> 01:02:05,000\n再见\n\n"
+	if srt.String() != wantSRT {
+		t.Errorf("SRT =\n%q\nwant\n%q", srt.String(), wantSRT)
+	}
+
+	var vtt bytes.Buffer
+	if err := WriteVTT(&vtt, cues); err != nil {
+		t.Fatal(err)
+	}
+	wantVTT := "WEBVTT\n\n00:00:00.000 --> 00:00:01.500\n你好\n世界\n\n01:02:03.004 --> 01:02:05.000\n再见\n\n"
+	if vtt.String() != wantVTT {
+		t.Errorf("VTT =\n%q\nwant\n%q", vtt.String(), wantVTT)
+	}
+
+	var js bytes.Buffer
+	if err := WriteJSON(&js, words("你", "<b>")); err != nil {
+		t.Fatal(err)
+	}
+	compact := strings.Join(strings.Fields(js.String()), "")
+	if want := `[{"text":"你","start_ms":0,"end_ms":100},{"text":"<b>","start_ms":100,"end_ms":200}]`; compact != want {
+		t.Errorf("JSON = %s", compact)
+	}
+}
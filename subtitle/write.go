@@ -0,0 +1,56 @@
+package subtitle
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WriteSRT 按 SRT 格式写入字幕，序号从 1 开始
+func WriteSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, c := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(c.Start, ','), timestamp(c.End, ','), c.Text())
+	}
+	return bw.Flush()
+}
+
+// WriteVTT 按 WebVTT 格式写入字幕
+func WriteVTT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		fmt.Fprintf(bw, "%s --> %s\n%s\n\n", timestamp(c.Start, '.'), timestamp(c.End, '.'), c.Text())
+	}
+	return bw.Flush()
+}
+
+// jsonWord JSON 时间轴中的字词，时间单位为毫秒
+type jsonWord struct {
+	Text    string `json:"text"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+}
+
+// WriteJSON 将字词时间轴写入为 JSON 数组，例如 [{"text":"你","start_ms":25,"end_ms":185}]
+func WriteJSON(w io.Writer, words []Word) error {
+	out := make([]jsonWord, 0, len(words))
+	for _, word := range words {
+		out = append(out, jsonWord{Text: word.Text, StartMs: word.Start.Milliseconds(), EndMs: word.End.Milliseconds()})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// timestamp 格式化为 hh:mm:ss,mmm，SRT 使用逗号，WebVTT 使用点号分隔毫秒
+func timestamp(d time.Duration, sep byte) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,100 @@
+package go_byte_tts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jefferyjob/go-easy-utils/anyUtil"
+	"github.com/zmexing/go-byte-tts/audioinfo"
+)
+
+const (
+	// 未设置 audio.encoding 时服务端返回的格式
+	defaultEncoding = "pcm"
+)
+
+// SynthesisResult 短文本语音合成的结果
+type SynthesisResult struct {
+	Audio    []byte          // 解码后的音频
+	Encoding string          // 音频编码，与请求的 audio.encoding 一致
+	ReqID    string          // 请求标识，重试时为最后一次请求的标识
+	Duration time.Duration   // 音频时长，服务端未返回时为0，参见 Info
+	Addition *RepAddition    // 服务端返回的附加信息，例如时长和前端信息，未返回时为 nil
+	Cached   bool            // 是否命中缓存，参见 [WithCache]
+	Timeline *Timeline       // 字词和音素的时间戳，请求设置 with_frontend 时返回，否则为 nil
+	Info     *audioinfo.Info // 解析音频得到的时长、采样率、声道数和码率，无法解析时为 nil
+}
+
+// Synthesize 短文本语音合成，返回解码后的音频
+// 服务端返回码不为成功时返回 *APIError
+func (g *GoTTS) Synthesize(ctx context.Context, req *SynthesisRequest) (*SynthesisResult, error) {
+	params, err := req.Params()
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	return g.synthesize(ctx, params)
+}
+
+// synthesize 合成并解码一次短文本请求
+func (g *GoTTS) synthesize(ctx context.Context, params map[string]map[string]any) (*SynthesisResult, error) {
+	resp, funcClose, err := g.TextToVoiceContext(ctx, params)
+	defer funcClose()
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("http io ReadAll error: %w", err)
+	}
+
+	var rep Rep
+	if err := json.Unmarshal(respBody, &rep); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal error: %w", err)
+	}
+
+	audio, err := decodeRepAudio(&rep)
+	if err != nil {
+		return nil, err
+	}
+
+	encoding := anyUtil.AnyToStr(params["audio"]["encoding"])
+	if encoding == "" {
+		encoding = defaultEncoding
+	}
+	result := &SynthesisResult{
+		Audio:    audio,
+		Encoding: encoding,
+		ReqID:    rep.ReqID,
+		Addition: rep.Addition,
+		Cached:   resp.Header.Get(CacheStatusHeader) == "HIT",
+	}
+	result.Duration = rep.Addition.duration()
+	// 前端信息只是附加的时间戳，解析失败不影响合成结果
+	if timeline, err := rep.Addition.Timeline(); err == nil {
+		result.Timeline = timeline
+	}
+	rate, _ := anyUtil.AnyToInt(params["audio"]["rate"])
+	if info, err := audioinfo.Probe(audio, encoding, audioinfo.PCMFormat{SampleRate: rate}); err == nil {
+		result.Info = info
+	}
+	return result, nil
+}
+
+// duration 附加信息中的音频时长，包含结尾的静音，未返回或无法解析时为0
+// 时长单位为毫秒，可能带小数
+func (a *RepAddition) duration() time.Duration {
+	if a == nil || a.Duration == "" {
+		return 0
+	}
+	ms, err := strconv.ParseFloat(a.Duration, 64)
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(math.Round(ms * float64(time.Millisecond)))
+}
@@ -0,0 +1,81 @@
+package go_byte_tts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSynthesizeOffline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_ = json.NewEncoder(w).Encode(Rep{
+			ReqID: params["request"]["reqid"].(string),
+			Code:  3000,
+			Data:  base64.StdEncoding.EncodeToString([]byte("audio")),
+			Addition: &RepAddition{
+				Duration: "1960",
+				Frontend: `{"words":[]}`,
+			},
+		})
+	}))
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Request.Reqid = "reqid-1"
+	result, err := tts.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Synthesize err = %v", err)
+	}
+	if string(result.Audio) != "audio" || result.Encoding != "mp3" || result.ReqID != "reqid-1" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Duration != time.Millisecond*1960 {
+		t.Errorf("Duration = %v, want 1.96s", result.Duration)
+	}
+	if result.Addition == nil || result.Addition.Frontend != `{"words":[]}` {
+		t.Errorf("Addition = %+v", result.Addition)
+	}
+}
+
+func TestSynthesizeDefaults(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Audio.Encoding = ""
+	result, err := tts.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Synthesize err = %v", err)
+	}
+	if result.Encoding != "pcm" || result.Duration != 0 || result.Addition != nil {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestSynthesizeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Rep{Code: 3001, Message: "invalid voice_type"})
+	}))
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	_, err := tts.Synthesize(context.Background(), newTestRequest())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 3001 {
+		t.Fatalf("err = %v, want APIError 3001", err)
+	}
+
+	req := newTestRequest()
+	req.Audio.VoiceType = ""
+	if _, err := tts.Synthesize(context.Background(), req); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
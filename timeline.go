@@ -0,0 +1,125 @@
+package go_byte_tts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/internal"
+	"github.com/zmexing/go-byte-tts/subtitle"
+)
+
+// 请求前端信息时使用的 request.frontend_type，返回字词和音素的时间戳
+const frontendTypeTimestamp = "unitTson"
+
+// Timeline 音频中字词和音素的时间戳，来自返回的 addition.frontend
+// Words 可以直接用于 [subtitle.Cues] 生成字幕
+type Timeline struct {
+	Words    []subtitle.Word
+	Phonemes []Phoneme
+}
+
+// Phoneme 单个音素及其在音频中的起止时间
+type Phoneme struct {
+	Phone string
+	Start time.Duration
+	End   time.Duration
+}
+
+// frontend addition.frontend 的内容，时间单位为秒
+type frontend struct {
+	Words []struct {
+		Word      string  `json:"word"`
+		StartTime float64 `json:"start_time"`
+		EndTime   float64 `json:"end_time"`
+	} `json:"words"`
+	Phonemes []struct {
+		Phone     string  `json:"phone"`
+		StartTime float64 `json:"start_time"`
+		EndTime   float64 `json:"end_time"`
+	} `json:"phonemes"`
+}
+
+// ParseFrontend 解析 addition.frontend 中的字词和音素时间戳
+func ParseFrontend(s string) (*Timeline, error) {
+	var f frontend
+	if err := json.Unmarshal([]byte(s), &f); err != nil {
+		return nil, fmt.Errorf("parse frontend error: %w", err)
+	}
+	t := &Timeline{}
+	for _, w := range f.Words {
+		t.Words = append(t.Words, subtitle.Word{Text: w.Word, Start: seconds(w.StartTime), End: seconds(w.EndTime)})
+	}
+	for _, p := range f.Phonemes {
+		t.Phonemes = append(t.Phonemes, Phoneme{Phone: p.Phone, Start: seconds(p.StartTime), End: seconds(p.EndTime)})
+	}
+	return t, nil
+}
+
+// seconds 秒转换为时长，精确到毫秒
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s*1000)) * time.Millisecond
+}
+
+// Timeline 解析附加信息中的时间戳，未返回前端信息时返回 nil
+func (a *RepAddition) Timeline() (*Timeline, error) {
+	if a == nil || a.Frontend == "" {
+		return nil, nil
+	}
+	return ParseFrontend(a.Frontend)
+}
+
+// End 最后一个字词或音素的结束时间
+func (t *Timeline) End() time.Duration {
+	var end time.Duration
+	for _, w := range t.Words {
+		if w.End > end {
+			end = w.End
+		}
+	}
+	for _, p := range t.Phonemes {
+		if p.End > end {
+			end = p.End
+		}
+	}
+	return end
+}
+
+// appendShifted 追加 other 中的时间戳，所有时间加上 offset
+func (t *Timeline) appendShifted(other *Timeline, offset time.Duration) {
+	for _, w := range other.Words {
+		w.Start += offset
+		w.End += offset
+		t.Words = append(t.Words, w)
+	}
+	for _, p := range other.Phonemes {
+		p.Start += offset
+		p.End += offset
+		t.Phonemes = append(t.Phonemes, p)
+	}
+}
+
+// TextToJoinVoiceTimeline 与 [GoTTS.TextToJoinVoiceWriter] 相同，同时返回整段音频的时间戳
+func (g *GoTTS) TextToJoinVoiceTimeline(ctx context.Context, params map[string]map[string]any, w io.Writer) (*Timeline, error) {
+	// 不修改调用方的参数
+	params = internal.DeepCopyParams(params)
+	if params["request"] == nil {
+		params["request"] = make(map[string]any)
+	}
+	params["request"]["with_frontend"] = 1
+	if _, ok := params["request"]["frontend_type"]; !ok {
+		params["request"]["frontend_type"] = frontendTypeTimestamp
+	}
+
+	result := &JoinResult{}
+	if err := g.joinVoice(ctx, params, w, result); err != nil {
+		return nil, err
+	}
+	if result.Timeline == nil {
+		return &Timeline{}, nil
+	}
+	return result.Timeline, nil
+}
@@ -0,0 +1,128 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+	"github.com/zmexing/go-byte-tts/subtitle"
+)
+
+func TestParseFrontend(t *testing.T) {
+	timeline, err := ParseFrontend(`{"words":[{"word":"你好","start_time":0.025,"end_time":0.4051}],` +
+		`"phonemes":[{"phone":"C0n","start_time":0.025,"end_time":0.1},{"phone":"C0i","start_time":0.1,"end_time":0.42}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := subtitle.Word{Text: "你好", Start: 25 * time.Millisecond, End: 405 * time.Millisecond}
+	if len(timeline.Words) != 1 || timeline.Words[0] != want {
+		t.Errorf("words = %+v", timeline.Words)
+	}
+	if len(timeline.Phonemes) != 2 || timeline.Phonemes[1].Phone != "C0i" || timeline.End() != 420*time.Millisecond {
+		t.Errorf("phonemes = %+v, end = %v", timeline.Phonemes, timeline.End())
+	}
+
+	if _, err := ParseFrontend("not json"); err == nil {
+		t.Error("invalid frontend should fail")
+	}
+	if timeline, err := (&RepAddition{Duration: "100"}).Timeline(); timeline != nil || err != nil {
+		t.Errorf("Timeline() = %v, %v", timeline, err)
+	}
+}
+
+func TestSynthesizeTimeline(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	srv.EnqueueTTS(bytettstest.Response{Frontend: `{"words":[{"word":"你好","start_time":0,"end_time":0.5}]}`})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Request.WithFrontend = 1
+	res, err := tts.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Timeline == nil || len(res.Timeline.Words) != 1 || res.Timeline.Words[0].End != 500*time.Millisecond {
+		t.Errorf("timeline = %+v", res.Timeline)
+	}
+}
+
+func TestTextToJoinVoiceTimeline(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	first := strings.Repeat("一", 300) + "。"
+	second := strings.Repeat("二", 100)
+	params := map[string]map[string]any{
+		"audio":   {"voice_type": "BV406_V2_streaming", "encoding": "pcm"},
+		"request": {"reqid": "reqid", "text": first + second, "operation": "query"},
+	}
+	var buf bytes.Buffer
+	timeline, err := tts.TextToJoinVoiceTimeline(context.Background(), params, &buf)
+	if err != nil {
+		t.Fatalf("TextToJoinVoiceTimeline err = %v", err)
+	}
+
+	reqs := srv.RequestsTo(bytettstest.PathTTS)
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2 chunks", len(reqs))
+	}
+	var body struct {
+		Request struct {
+			WithFrontend int    `json:"with_frontend"`
+			FrontendType string `json:"frontend_type"`
+		} `json:"request"`
+	}
+	if err := reqs[0].JSON(&body); err != nil || body.Request.WithFrontend != 1 || body.Request.FrontendType != frontendTypeTimestamp {
+		t.Errorf("request = %+v, %v", body.Request, err)
+	}
+
+	// 模拟服务每个字 100 毫秒，第二个分片的时间戳从第一个分片的时长开始
+	if n := len(timeline.Words); n != 401 {
+		t.Fatalf("words = %d, want 401", n)
+	}
+	offset := 301 * bytettstest.FrontendWordDuration
+	if w := timeline.Words[301]; w.Text != "二" || w.Start != offset || w.End != offset+bytettstest.FrontendWordDuration {
+		t.Errorf("second chunk word = %+v", w)
+	}
+	for i := 1; i < len(timeline.Words); i++ {
+		if timeline.Words[i].Start < timeline.Words[i-1].End {
+			t.Fatalf("word %d overlaps: %+v", i, timeline.Words[i])
+		}
+	}
+}
+
+func TestTextToJoinVoiceTimelineDuration(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+	// 第一个分片的最后一个字在 0.5 秒结束，之后还有 0.3 秒静音
+	srv.EnqueueTTS(
+		bytettstest.Response{Duration: "800.4", Frontend: `{"words":[{"word":"一","start_time":0,"end_time":0.5}]}`},
+		bytettstest.Response{Duration: "500", Frontend: `{"words":[{"word":"二","start_time":0,"end_time":0.5}]}`},
+	)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithMaxConcurrency(1))
+
+	params := map[string]map[string]any{
+		"audio":   {"voice_type": "BV406_V2_streaming", "encoding": "mp3"},
+		"request": {"reqid": "reqid", "text": strings.Repeat("一", 300) + "。" + strings.Repeat("二", 100), "operation": "query"},
+	}
+	timeline, err := tts.TextToJoinVoiceTimeline(context.Background(), params, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// mp3 无法解析时长，使用 addition.duration 而不是最后一个字的结束时间
+	if len(timeline.Words) != 2 || timeline.Words[1].Start != 800400*time.Microsecond {
+		t.Errorf("words = %+v", timeline.Words)
+	}
+	// 不修改调用方的参数
+	if _, ok := params["request"]["with_frontend"]; ok {
+		t.Errorf("params were modified: %v", params["request"])
+	}
+	if _, ok := params["request"]["frontend_type"]; ok {
+		t.Errorf("params were modified: %v", params["request"])
+	}
+}
@@ -1,6 +1,7 @@
 package go_byte_tts
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -12,21 +13,33 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
+	// 默认服务地址
+	DefaultBaseURL = "https://openspeech.bytedance.com"
 	// 短文本语音合成
-	apiTts = "https://openspeech.bytedance.com/api/v1/tts"
+	apiTts = "/api/v1/tts"
+	// 流式语音合成（WebSocket 二进制协议）
+	apiTtsWs = "/api/v1/tts/ws_binary"
 	// 创建长文本语音
-	apiLongTts        = "https://openspeech.bytedance.com/api/v1/tts_async/submit"
-	apiLongEmotionTts = "https://openspeech.bytedance.com/api/v1/tts_async_with_emotion/submit"
+	apiLongTts        = "/api/v1/tts_async/submit"
+	apiLongEmotionTts = "/api/v1/tts_async_with_emotion/submit"
 	// 查询长文本语音合成结果
-	apiLongTtsQuery        = "https://openspeech.bytedance.com/api/v1/tts_async/query"
-	apiLongEmotionTtsQuery = "https://openspeech.bytedance.com/api/v1/tts_async_with_emotion/query"
+	apiLongTtsQuery        = "/api/v1/tts_async/query"
+	apiLongEmotionTtsQuery = "/api/v1/tts_async_with_emotion/query"
 	// 长文本语音资源标识
 	apiLongResource        = "volc.tts_async.default"
 	apiLongEmotionResource = "volc.tts_async.emotion"
+	// 默认请求超时时间
+	defaultTimeout = time.Second * 60
+	// 默认每个host保持的空闲连接数，需要覆盖 TextToJoinVoiceDisk 的并发请求
+	defaultMaxIdleConnsPerHost = 32
+	// TextToJoinVoiceDisk 默认同时合成的分片数
+	defaultMaxConcurrency = 4
 )
 
 type GoTTSInter interface {
@@ -38,33 +51,184 @@ type GoTTSInter interface {
 
 	// TextToJoinVoiceDisk 文本转语音并写入磁盘
 	// 方法 [TextToVoiceDisk] 因为超过1024字节提示系统错误，所以建议使用 [TextToJoinVoiceDisk]
-	// 该方法会自动将文本按照 1024 字节将文本拆开，最后分片生成后合并成一个语音文件
+	// 该方法会自动将文本拆成不超过 1024 字节的分片，优先在句子和标点处切分，参见 [WithSplitter]，最后分片生成后合并成一个语音文件
 	TextToJoinVoiceDisk(params map[string]map[string]any, outFile *os.File) error
 
 	// LongTextToVoiceCreate 长文本语音合成 任务创建
 	// 创建合成任务的频率限制为10 QPS，请勿一次性提交过多任务。
+	// 默认会在客户端按 10 QPS 限流，参见 [WithRateLimits]
 	LongTextToVoiceCreate(params map[string]any) (*TtsAsyncRep, error)
 
 	// LongTextToVoiceId 长文本语音合成 任务查询
 	// 音频URL，有效期为1个小时，请及时下载
 	LongTextToVoiceId(id string) (*TtsAsyncQueryRep, error)
+
+	// TextToVoiceRequest 使用结构化参数的 [TextToVoice]
+	TextToVoiceRequest(req *SynthesisRequest) (*http.Response, func(), error)
+
+	// TextToVoiceDiskRequest 使用结构化参数的 [TextToVoiceDisk]
+	TextToVoiceDiskRequest(req *SynthesisRequest, outFile *os.File) error
+
+	// TextToJoinVoiceDiskRequest 使用结构化参数的 [TextToJoinVoiceDisk]
+	TextToJoinVoiceDiskRequest(req *SynthesisRequest, outFile *os.File) error
+
+	// TextToVoiceContext 使用 ctx 控制超时和取消的 [TextToVoice]
+	TextToVoiceContext(ctx context.Context, params map[string]map[string]any) (*http.Response, func(), error)
+
+	// TextToVoiceDiskContext 使用 ctx 控制超时和取消的 [TextToVoiceDisk]
+	TextToVoiceDiskContext(ctx context.Context, params map[string]map[string]any, outFile *os.File) error
+
+	// TextToJoinVoiceDiskContext 使用 ctx 控制超时和取消的 [TextToJoinVoiceDisk]
+	// ctx 取消后，尚未完成的分片请求会一并取消
+	TextToJoinVoiceDiskContext(ctx context.Context, params map[string]map[string]any, outFile *os.File) error
+
+	// LongTextToVoiceCreateContext 使用 ctx 控制超时和取消的 [LongTextToVoiceCreate]
+	LongTextToVoiceCreateContext(ctx context.Context, params map[string]any) (*TtsAsyncRep, error)
+
+	// LongTextToVoiceIdContext 使用 ctx 控制超时和取消的 [LongTextToVoiceId]
+	LongTextToVoiceIdContext(ctx context.Context, id string) (*TtsAsyncQueryRep, error)
+
+	// TextToVoiceRequestContext 使用 ctx 控制超时和取消的 [TextToVoiceRequest]
+	TextToVoiceRequestContext(ctx context.Context, req *SynthesisRequest) (*http.Response, func(), error)
+
+	// TextToVoiceDiskRequestContext 使用 ctx 控制超时和取消的 [TextToVoiceDiskRequest]
+	TextToVoiceDiskRequestContext(ctx context.Context, req *SynthesisRequest, outFile *os.File) error
+
+	// TextToJoinVoiceDiskRequestContext 使用 ctx 控制超时和取消的 [TextToJoinVoiceDiskRequest]
+	TextToJoinVoiceDiskRequestContext(ctx context.Context, req *SynthesisRequest, outFile *os.File) error
+
+	// WaitForLongTextTask 轮询长文本任务直到合成结束
+	// 合成成功返回最后一次查询结果，合成失败返回 *TaskFailedError
+	WaitForLongTextTask(ctx context.Context, taskId string, opts WaitOptions) (*TtsAsyncQueryRep, error)
+
+	// LongTextToVoiceDownload 创建长文本任务，等待合成完成后将音频下载写入 w
+	LongTextToVoiceDownload(ctx context.Context, params map[string]any, w io.Writer, opts WaitOptions) (*TtsAsyncQueryRep, error)
+
+	// DownloadLongTextAudio 下载长文本任务合成的音频并写入 w
+	// 下载中断后自动续传，音频URL过期后返回 [ErrAudioURLExpired]
+	DownloadLongTextAudio(ctx context.Context, result *TtsAsyncQueryRep, w io.Writer) error
+
+	// TextToVoiceWriter 文本转语音并写入 w，w 可以是文件、bytes.Buffer、http.ResponseWriter 等
+	TextToVoiceWriter(ctx context.Context, params map[string]map[string]any, w io.Writer) error
+
+	// TextToJoinVoiceWriter 超长文本自动分片合成，按顺序写入 w，参见 [TextToJoinVoiceDisk]
+	TextToJoinVoiceWriter(ctx context.Context, params map[string]map[string]any, w io.Writer) error
+
+	// TextToVoiceRequestWriter 使用结构化参数的 [TextToVoiceWriter]
+	TextToVoiceRequestWriter(ctx context.Context, req *SynthesisRequest, w io.Writer) error
+
+	// TextToJoinVoiceRequestWriter 使用结构化参数的 [TextToJoinVoiceWriter]
+	TextToJoinVoiceRequestWriter(ctx context.Context, req *SynthesisRequest, w io.Writer) error
+
+	// TextToJoinVoiceTimeline 与 [TextToJoinVoiceWriter] 相同，同时返回整段音频中字词和音素的时间戳
+	// 自动设置 request.with_frontend，每个分片的时间戳加上之前分片的音频时长，可以用 subtitle 包生成字幕
+	TextToJoinVoiceTimeline(ctx context.Context, params map[string]map[string]any, w io.Writer) (*Timeline, error)
+
+	// TextToJoinVoiceResult 与 [TextToJoinVoiceWriter] 相同，同时返回每个分片的文本、时长、音频信息和在拼接音频中的起始时间
+	TextToJoinVoiceResult(ctx context.Context, params map[string]map[string]any, w io.Writer) (*JoinResult, error)
+
+	// TextToVoiceReader 文本转语音，返回音频流，超长文本自动分片，每个分片合成后即可读取
+	// 使用完毕后必须调用 Close，提前 Close 会取消尚未完成的请求
+	TextToVoiceReader(ctx context.Context, params map[string]map[string]any) (io.ReadCloser, error)
+
+	// Synthesize 短文本语音合成，返回解码后的音频和附加信息，服务端返回码不为成功时返回 *APIError
+	Synthesize(ctx context.Context, req *SynthesisRequest) (*SynthesisResult, error)
+
+	// StreamSynthesize 通过 WebSocket 流式合成，边合成边返回音频分片，可以在合成结束前开始播放
+	// 使用完毕后必须调用 [AudioStream.Close]
+	StreamSynthesize(ctx context.Context, req *SynthesisRequest) (*AudioStream, error)
 }
 
 type GoTTS struct {
-	ctx     context.Context
-	appId   string // 应用标识
-	cluster string // 业务集群
-	token   string // 应用令牌
-	emotion bool   // 是否启用情感预测
+	ctx       context.Context // 不带 Context 后缀的方法使用的默认 ctx
+	appId     string          // 应用标识
+	cluster   string          // 业务集群
+	token     string          // 应用令牌
+	emotion   bool            // 是否启用情感预测
+	endpoints Endpoints       // 接口地址，所有选项执行后由 baseURL 和 overrides 生成
+	baseURL   string          // WithBaseURL 设置的服务地址
+	overrides Endpoints       // WithEndpoints 单独设置的接口地址
+
+	retryPolicy RetryPolicy  // 重试策略
+	rateLimits  RateLimits   // 限流配置
+	limiters    rateLimiters // 根据限流配置创建的限流器
+
+	httpClient *http.Client      // 共享连接池的 http 客户端
+	transport  http.RoundTripper // WithTransport 设置的 RoundTripper，所有选项执行后应用到 httpClient
+	splitter   Splitter          // 超长文本的分片策略
+	cache      Cache             // 短文本合成结果的缓存，为 nil 时不缓存
+
+	strictVoices bool // 拒绝音色目录中没有的音色
+	pcmToWav     bool // pcm 写入时添加 WAV 头部
+
+	joinSmoothing *JoinSmoothing // 分片拼接时处理边界的静音，为 nil 时直接拼接
+
+	maxConcurrency int // TextToJoinVoiceDisk 同时合成的分片数
+}
+
+// Endpoints 各接口的完整地址
+type Endpoints struct {
+	Tts                 string // 短文本语音合成
+	TtsWs               string // 流式语音合成，ws:// 或 wss:// 地址
+	LongTts             string // 创建长文本语音
+	LongEmotionTts      string // 创建长文本语音（情感预测版）
+	LongTtsQuery        string // 查询长文本语音合成结果
+	LongEmotionTtsQuery string // 查询长文本语音合成结果（情感预测版）
+}
+
+// NewEndpoints 根据服务地址生成全部接口地址，流式接口的地址使用对应的 ws / wss 协议
+func NewEndpoints(baseURL string) Endpoints {
+	baseURL = strings.TrimRight(baseURL, "/")
+	wsBaseURL := baseURL
+	if strings.HasPrefix(wsBaseURL, "https://") {
+		wsBaseURL = "wss://" + strings.TrimPrefix(wsBaseURL, "https://")
+	} else if strings.HasPrefix(wsBaseURL, "http://") {
+		wsBaseURL = "ws://" + strings.TrimPrefix(wsBaseURL, "http://")
+	}
+	return Endpoints{
+		Tts:                 baseURL + apiTts,
+		TtsWs:               wsBaseURL + apiTtsWs,
+		LongTts:             baseURL + apiLongTts,
+		LongEmotionTts:      baseURL + apiLongEmotionTts,
+		LongTtsQuery:        baseURL + apiLongTtsQuery,
+		LongEmotionTtsQuery: baseURL + apiLongEmotionTtsQuery,
+	}
 }
 
 type Option func(*GoTTS)
 
 func NewGoTTS(ctx context.Context, opts ...Option) (GoTTSInter, error) {
-	g := &GoTTS{ctx: ctx}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	g := &GoTTS{
+		ctx:        ctx,
+		baseURL:    DefaultBaseURL,
+		rateLimits: DefaultRateLimits,
+	}
 	for _, o := range opts {
 		o(g)
 	}
+	g.endpoints = NewEndpoints(g.baseURL)
+	g.endpoints.override(g.overrides)
+	if g.transport != nil {
+		c := &http.Client{}
+		if g.httpClient != nil {
+			*c = *g.httpClient
+		}
+		c.Transport = g.transport
+		g.httpClient = c
+	}
+	if g.httpClient == nil {
+		g.httpClient = newDefaultHTTPClient()
+	}
+	if g.splitter == nil {
+		g.splitter = SentenceSplitter
+	}
+	if g.maxConcurrency <= 0 {
+		g.maxConcurrency = defaultMaxConcurrency
+	}
+	g.limiters = newRateLimiters(g.rateLimits)
 	// 参数验证
 	if g.appId == "" {
 		return nil, errors.New("the parameter appid is defined as")
@@ -102,36 +266,111 @@ func WithEmotion() Option {
 	}
 }
 
-// TextToVoiceDisk 文本转语音并写入磁盘
-func (g *GoTTS) TextToVoiceDisk(params map[string]map[string]any, outFile *os.File) error {
-	resp, funcClose, err := g.TextToVoice(params)
-	defer funcClose()
-	if err != nil {
-		return err
+// WithHTTPClient 使用自定义的 http.Client，所有请求共用该客户端的连接池
+// 如果 client 设置了 Timeout，则使用该超时时间代替默认的 60 秒
+func WithHTTPClient(client *http.Client) Option {
+	return func(g *GoTTS) {
+		g.httpClient = client
 	}
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+// WithTransport 使用自定义的 http.RoundTripper，可用于代理、mTLS、链路追踪等
+// 与 WithHTTPClient 同时使用时与选项顺序无关，替换该客户端的 Transport，不修改调用方的客户端
+func WithTransport(transport http.RoundTripper) Option {
+	return func(g *GoTTS) {
+		g.transport = transport
 	}
+}
 
-	rep := &Rep{}
-	err = json.Unmarshal(respBody, rep)
-	if err != nil {
-		return err
+// WithMaxConcurrency 设置 TextToJoinVoiceDisk 同时合成的分片数，默认为4，小于等于0时使用默认值
+// 同一时间内存中最多保留 n 个分片的音频，任意分片失败后会取消其余分片
+func WithMaxConcurrency(n int) Option {
+	return func(g *GoTTS) {
+		g.maxConcurrency = n
+	}
+}
+
+// WithBaseURL 设置服务地址，用于测试服务、代理或私有网关
+// 例如 http://127.0.0.1:8080 ，所有接口路径保持不变
+func WithBaseURL(baseURL string) Option {
+	return func(g *GoTTS) {
+		g.baseURL = baseURL
 	}
+}
 
-	audio, err := base64.StdEncoding.DecodeString(rep.Data)
+// WithEndpoints 单独设置接口地址，未设置的字段使用 WithBaseURL 的服务地址，与选项顺序无关
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(g *GoTTS) {
+		g.overrides.override(endpoints)
+	}
+}
+
+// override 使用 o 中不为空的地址替换对应的接口地址
+func (e *Endpoints) override(o Endpoints) {
+	if o.Tts != "" {
+		e.Tts = o.Tts
+	}
+	if o.TtsWs != "" {
+		e.TtsWs = o.TtsWs
+	}
+	if o.LongTts != "" {
+		e.LongTts = o.LongTts
+	}
+	if o.LongEmotionTts != "" {
+		e.LongEmotionTts = o.LongEmotionTts
+	}
+	if o.LongTtsQuery != "" {
+		e.LongTtsQuery = o.LongTtsQuery
+	}
+	if o.LongEmotionTtsQuery != "" {
+		e.LongEmotionTtsQuery = o.LongEmotionTtsQuery
+	}
+}
+
+// newDefaultHTTPClient 创建默认的 http 客户端，连接池在同一个 GoTTS 的所有请求间复用
+func newDefaultHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	return &http.Client{Transport: transport}
+}
+
+// newHTTPClient 基于共享的 http 客户端创建请求，请求受 ctx 控制
+func (g *GoTTS) newHTTPClient(ctx context.Context, opts ...internal.Option) *internal.HTTPClient {
+	base := []internal.Option{internal.WithClient(g.httpClient)}
+	if g.httpClient.Timeout == 0 {
+		base = append(base, internal.WithTimeout(defaultTimeout))
+	}
+	return internal.NewHTTPClient(ctx, append(base, opts...)...)
+}
+
+// TextToVoiceDisk 文本转语音并写入磁盘
+func (g *GoTTS) TextToVoiceDisk(params map[string]map[string]any, outFile *os.File) error {
+	return g.TextToVoiceDiskContext(g.ctx, params, outFile)
+}
+
+// TextToVoiceDiskContext 文本转语音并写入磁盘
+func (g *GoTTS) TextToVoiceDiskContext(ctx context.Context, params map[string]map[string]any, outFile *os.File) error {
+	return g.TextToVoiceWriter(ctx, params, outFile)
+}
+
+// TextToVoiceWriter 文本转语音并写入 w
+func (g *GoTTS) TextToVoiceWriter(ctx context.Context, params map[string]map[string]any, w io.Writer) error {
+	result, err := g.synthesize(ctx, params)
 	if err != nil {
 		return err
 	}
-
-	return internal.WriteBytesToDisk(audio, outFile)
+	return g.writeAudio(params, result.Audio, w)
 }
 
 // TextToVoice 文本转语音
 func (g *GoTTS) TextToVoice(params map[string]map[string]any) (*http.Response, func(), error) {
-	if err := internal.CheckParams(params); err != nil {
+	return g.TextToVoiceContext(g.ctx, params)
+}
+
+// TextToVoiceContext 文本转语音
+// 返回的 http.Response 在读取完成前仍受 ctx 控制
+func (g *GoTTS) TextToVoiceContext(ctx context.Context, params map[string]map[string]any) (*http.Response, func(), error) {
+	if err := internal.CheckParams(params, checkParamsSSML, g.checkParamsVoice); err != nil {
 		return nil, func() {}, fmt.Errorf("invalid parameters: %w", err)
 	}
 
@@ -142,10 +381,49 @@ func (g *GoTTS) TextToVoice(params map[string]map[string]any) (*http.Response, f
 	params["app"]["token"] = "access_token"
 	params["app"]["cluster"] = g.cluster
 
-	jsonStr, err := json.Marshal(params)
+	var cacheKey string
+	if g.cache != nil {
+		cacheKey = CacheKey(params)
+		if resp, ok := g.cachedResponse(cacheKey, params); ok {
+			return resp, func() { resp.Body.Close() }, nil
+		}
+	}
+
+	var resp *http.Response
+	var respBody []byte
+	err := g.retry(ctx, func(attempt int) error {
+		attemptParams := params
+		if attempt > 0 {
+			// 每次重试使用新的请求标识
+			attemptParams = internal.DeepCopyParams(params)
+			attemptParams["request"]["reqid"] = uuid.NewString()
+		}
+		var err error
+		resp, respBody, err = g.sendTextToVoice(ctx, attemptParams)
+		return err
+	})
 	if err != nil {
 		return nil, func() {}, err
 	}
+	if g.cache != nil {
+		g.storeCache(cacheKey, respBody)
+	}
+
+	// 响应体已经读取用于校验返回码，重新包装后交给调用方
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, func() { resp.Body.Close() }, nil
+}
+
+// sendTextToVoice 发送一次短文本语音合成请求，读取并校验响应
+func (g *GoTTS) sendTextToVoice(ctx context.Context, params map[string]map[string]any) (*http.Response, []byte, error) {
+	if err := g.waitRateLimit(ctx, g.limiters.synthesis); err != nil {
+		return nil, nil, err
+	}
+
+	jsonStr, err := json.Marshal(params)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	body := map[string]any{
 		"json": string(jsonStr),
@@ -155,37 +433,169 @@ func (g *GoTTS) TextToVoice(params map[string]map[string]any) (*http.Response, f
 		"Authorization": fmt.Sprintf("Bearer;%s", g.token),
 	}
 
-	client := internal.NewHTTPClient(
-		g.ctx,
-		internal.WithTimeout(time.Second*60),
+	client := g.newHTTPClient(
+		ctx,
 		internal.WithHeader(header),
 		internal.WithContentType(internal.HttpJson),
 	)
-	resp, funcClose, err := client.SendRequest(http.MethodPost, apiTts, body)
+	resp, funcClose, err := client.SendRequest(http.MethodPost, g.endpoints.Tts, body)
+	defer funcClose()
 	if err != nil {
-		return nil, funcClose, err
+		return nil, nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, funcClose, fmt.Errorf("http response code failed: %w", errors.New(resp.Status))
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("http io ReadAll error: %w", err)
 	}
 
-	if resp.ContentLength == 0 {
-		return nil, funcClose, errors.New("http response ContentLength=0")
+	if _, err := checkTtsResponse(resp.StatusCode, respBody); err != nil {
+		return nil, nil, err
 	}
+	return resp, respBody, nil
+}
 
-	return resp, funcClose, nil
+// checkTtsResponse 校验短文本语音合成的 HTTP 状态码和返回码
+func checkTtsResponse(status int, body []byte) (*Rep, error) {
+	if status == http.StatusOK && len(body) == 0 {
+		return nil, errors.New("http response ContentLength=0")
+	}
+	rep := &Rep{}
+	if err := json.Unmarshal(body, rep); err != nil {
+		if status != http.StatusOK {
+			return nil, newAPIError(status, 0, "", "")
+		}
+		return nil, fmt.Errorf("http response body Unmarshal error: %w", err)
+	}
+	if status != http.StatusOK || rep.Code != codeSuccess {
+		return nil, newAPIError(status, rep.Code, rep.Message, rep.ReqID)
+	}
+	return rep, nil
+}
+
+// decodeRepAudio 解码返回的音频数据
+func decodeRepAudio(rep *Rep) ([]byte, error) {
+	audio, err := base64.StdEncoding.DecodeString(rep.Data)
+	if err != nil {
+		return nil, fmt.Errorf("base64 decode error: %w", err)
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("reqid %s: %w", rep.ReqID, ErrEmptyAudio)
+	}
+	return audio, nil
+}
+
+// checkAsyncResponse 校验长文本语音合成的 HTTP 状态码和返回码
+func checkAsyncResponse(status int, body []byte) error {
+	var rep struct {
+		Reqid   string `json:"reqid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &rep); err != nil {
+		if status != http.StatusOK {
+			return newAPIError(status, 0, "", "")
+		}
+		return fmt.Errorf("http response body Unmarshal error: %w", err)
+	}
+	if status != http.StatusOK || rep.Code != codeAsyncSuccess {
+		return newAPIError(status, rep.Code, rep.Message, rep.Reqid)
+	}
+	return nil
+}
+
+// TextToVoiceRequest 使用结构化参数的 [GoTTS.TextToVoice]
+func (g *GoTTS) TextToVoiceRequest(req *SynthesisRequest) (*http.Response, func(), error) {
+	return g.TextToVoiceRequestContext(g.ctx, req)
+}
+
+// TextToVoiceRequestContext 使用结构化参数的 [GoTTS.TextToVoiceContext]
+func (g *GoTTS) TextToVoiceRequestContext(ctx context.Context, req *SynthesisRequest) (*http.Response, func(), error) {
+	params, err := req.Params()
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("invalid parameters: %w", err)
+	}
+	return g.TextToVoiceContext(ctx, params)
+}
+
+// TextToVoiceDiskRequest 使用结构化参数的 [GoTTS.TextToVoiceDisk]
+func (g *GoTTS) TextToVoiceDiskRequest(req *SynthesisRequest, outFile *os.File) error {
+	return g.TextToVoiceDiskRequestContext(g.ctx, req, outFile)
+}
+
+// TextToVoiceDiskRequestContext 使用结构化参数的 [GoTTS.TextToVoiceDiskContext]
+func (g *GoTTS) TextToVoiceDiskRequestContext(ctx context.Context, req *SynthesisRequest, outFile *os.File) error {
+	params, err := req.Params()
+	if err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	return g.TextToVoiceDiskContext(ctx, params, outFile)
+}
+
+// TextToJoinVoiceDiskRequest 使用结构化参数的 [GoTTS.TextToJoinVoiceDisk]
+func (g *GoTTS) TextToJoinVoiceDiskRequest(req *SynthesisRequest, outFile *os.File) error {
+	return g.TextToJoinVoiceDiskRequestContext(g.ctx, req, outFile)
+}
+
+// TextToJoinVoiceDiskRequestContext 使用结构化参数的 [GoTTS.TextToJoinVoiceDiskContext]
+func (g *GoTTS) TextToJoinVoiceDiskRequestContext(ctx context.Context, req *SynthesisRequest, outFile *os.File) error {
+	params, err := req.Params()
+	if err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	return g.TextToJoinVoiceDiskContext(ctx, params, outFile)
+}
+
+// TextToVoiceRequestWriter 使用结构化参数的 [GoTTS.TextToVoiceWriter]
+func (g *GoTTS) TextToVoiceRequestWriter(ctx context.Context, req *SynthesisRequest, w io.Writer) error {
+	params, err := req.Params()
+	if err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	return g.TextToVoiceWriter(ctx, params, w)
 }
 
+// TextToJoinVoiceRequestWriter 使用结构化参数的 [GoTTS.TextToJoinVoiceWriter]
+func (g *GoTTS) TextToJoinVoiceRequestWriter(ctx context.Context, req *SynthesisRequest, w io.Writer) error {
+	params, err := req.Params()
+	if err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	return g.TextToJoinVoiceWriter(ctx, params, w)
+}
+
+// LongTextToVoiceCreate 长文本语音合成 任务创建
 func (g *GoTTS) LongTextToVoiceCreate(params map[string]any) (*TtsAsyncRep, error) {
+	return g.LongTextToVoiceCreateContext(g.ctx, params)
+}
+
+// LongTextToVoiceCreateContext 长文本语音合成 任务创建
+func (g *GoTTS) LongTextToVoiceCreateContext(ctx context.Context, params map[string]any) (*TtsAsyncRep, error) {
 	params["appid"] = g.appId
-	params["reqid"] = uuid.NewString()
+
+	var result *TtsAsyncRep
+	// 创建任务不是幂等的，请求可能已经发出时不重试，参见 [RetryPolicy]
+	err := g.retryIf(ctx, g.retryPolicy.retryableCreate, func(attempt int) error {
+		// 每次请求（包括重试）使用新的请求标识
+		params["reqid"] = uuid.NewString()
+		var err error
+		result, err = g.sendLongTextToVoiceCreate(ctx, params)
+		return err
+	})
+	return result, err
+}
+
+// sendLongTextToVoiceCreate 发送一次长文本语音合成任务创建请求
+func (g *GoTTS) sendLongTextToVoiceCreate(ctx context.Context, params map[string]any) (*TtsAsyncRep, error) {
+	if err := g.waitRateLimit(ctx, g.limiters.create); err != nil {
+		return nil, err
+	}
 
 	// 是否使用情感预测版本
-	url := apiLongTts
+	url := g.endpoints.LongTts
 	resourceId := apiLongResource
 	if g.emotion {
-		url = apiLongEmotionTts
+		url = g.endpoints.LongEmotionTts
 		resourceId = apiLongEmotionResource
 	}
 
@@ -203,9 +613,8 @@ func (g *GoTTS) LongTextToVoiceCreate(params map[string]any) (*TtsAsyncRep, erro
 		"Resource-Id":   resourceId,
 	}
 
-	client := internal.NewHTTPClient(
-		g.ctx,
-		internal.WithTimeout(time.Second*60),
+	client := g.newHTTPClient(
+		ctx,
 		internal.WithHeader(header),
 		internal.WithContentType(internal.HttpJson),
 	)
@@ -216,15 +625,15 @@ func (g *GoTTS) LongTextToVoiceCreate(params map[string]any) (*TtsAsyncRep, erro
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http response code failed: %w", errors.New(resp.Status))
-	}
-
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("http io ReadAll error: %w", err)
 	}
 
+	if err := checkAsyncResponse(resp.StatusCode, respBody); err != nil {
+		return nil, err
+	}
+
 	var ttsAsyncRep TtsAsyncRep
 	err = json.Unmarshal(respBody, &ttsAsyncRep)
 	if err != nil {
@@ -234,12 +643,33 @@ func (g *GoTTS) LongTextToVoiceCreate(params map[string]any) (*TtsAsyncRep, erro
 	return &ttsAsyncRep, nil
 }
 
+// LongTextToVoiceId 长文本语音合成 任务查询
 func (g *GoTTS) LongTextToVoiceId(id string) (*TtsAsyncQueryRep, error) {
+	return g.LongTextToVoiceIdContext(g.ctx, id)
+}
+
+// LongTextToVoiceIdContext 长文本语音合成 任务查询
+func (g *GoTTS) LongTextToVoiceIdContext(ctx context.Context, id string) (*TtsAsyncQueryRep, error) {
+	var result *TtsAsyncQueryRep
+	err := g.retry(ctx, func(attempt int) error {
+		var err error
+		result, err = g.sendLongTextToVoiceId(ctx, id)
+		return err
+	})
+	return result, err
+}
+
+// sendLongTextToVoiceId 发送一次长文本语音合成任务查询请求
+func (g *GoTTS) sendLongTextToVoiceId(ctx context.Context, id string) (*TtsAsyncQueryRep, error) {
+	if err := g.waitRateLimit(ctx, g.limiters.query); err != nil {
+		return nil, err
+	}
+
 	// 是否使用情感预测版本
-	url := apiLongTtsQuery
+	url := g.endpoints.LongTtsQuery
 	resourceId := apiLongResource
 	if g.emotion {
-		url = apiLongEmotionTtsQuery
+		url = g.endpoints.LongEmotionTtsQuery
 		resourceId = apiLongEmotionResource
 	}
 
@@ -252,9 +682,8 @@ func (g *GoTTS) LongTextToVoiceId(id string) (*TtsAsyncQueryRep, error) {
 		"Resource-Id":   resourceId,
 	}
 
-	client := internal.NewHTTPClient(
-		g.ctx,
-		internal.WithTimeout(time.Second*60),
+	client := g.newHTTPClient(
+		ctx,
 		internal.WithHeader(header),
 	)
 
@@ -264,15 +693,15 @@ func (g *GoTTS) LongTextToVoiceId(id string) (*TtsAsyncQueryRep, error) {
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http response code failed: %w", errors.New(resp.Status))
-	}
-
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("http io ReadAll error: %w", err)
 	}
 
+	if err := checkAsyncResponse(resp.StatusCode, respBody); err != nil {
+		return nil, err
+	}
+
 	var result TtsAsyncQueryRep
 	err = json.Unmarshal(respBody, &result)
 	if err != nil {
@@ -281,78 +710,175 @@ func (g *GoTTS) LongTextToVoiceId(id string) (*TtsAsyncQueryRep, error) {
 	return &result, nil
 }
 
+// TextToJoinVoiceDisk 文本转语音并写入磁盘，超长文本自动分片合成
 func (g *GoTTS) TextToJoinVoiceDisk(params map[string]map[string]any, outFile *os.File) error {
-	text, _ := params["request"]["text"]
-	textList := internal.SplitText(anyUtil.AnyToStr(text), 1024)
-
-	// 协程并行处理多个文本列表
-	chWork := make(chan ChanJoinVoice, len(textList))
-	defer close(chWork)
-	for i, v := range textList {
-		newMap := internal.DeepCopyParams(params)
-		newMap["request"]["text"] = v
-
-		// 如果文本被拆开，则中间的连接停顿应该减小
-		if i != (len(textList) - 1) {
-			newMap["request"]["silence_duration"] = 50
-		}
+	return g.TextToJoinVoiceDiskContext(g.ctx, params, outFile)
+}
+
+// TextToJoinVoiceDiskContext 文本转语音并写入磁盘，超长文本自动分片合成
+func (g *GoTTS) TextToJoinVoiceDiskContext(ctx context.Context, params map[string]map[string]any, outFile *os.File) error {
+	return g.TextToJoinVoiceWriter(ctx, params, outFile)
+}
+
+// TextToJoinVoiceWriter 超长文本自动分片合成，按顺序拼接后写入 w
+// w 实现 io.Seeker 时 wav 边合成边写入，否则缓存全部音频后写入
+func (g *GoTTS) TextToJoinVoiceWriter(ctx context.Context, params map[string]map[string]any, w io.Writer) error {
+	return g.joinVoice(ctx, params, w, nil)
+}
 
-		go g.workTextToJoinVoiceDisk(newMap, i, chWork)
+// joinVoice 分片合成并按顺序拼接，result 不为 nil 时记录每个分片的结果
+func (g *GoTTS) joinVoice(ctx context.Context, params map[string]map[string]any, w io.Writer, result *JoinResult) error {
+	if err := internal.CheckParams(params, checkParamsSSML, g.checkParamsVoice); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	text, _ := params["request"]["text"]
+	textList, err := g.splitText(anyUtil.AnyToStr(text), isSSML(params))
+	if err != nil {
+		return err
 	}
 
-	// 按照顺序拼接协程的数据
-	resMap := make(map[int][]byte, len(textList))
-	for i := 0; i < len(textList); i++ {
-		wordRes := <-chWork
+	// 任意分片失败或调用方取消时，取消其余分片的请求，并等待所有协程退出
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// 记录第一个失败分片的错误，不必等到按顺序拼接到该分片
+	var failMu sync.Mutex
+	var failErr error
+	fail := func(err error) {
+		failMu.Lock()
+		if failErr == nil {
+			failErr = err
+		}
+		failMu.Unlock()
+		cancel()
+	}
+	firstErr := func() error {
+		failMu.Lock()
+		defer failMu.Unlock()
+		if failErr != nil {
+			return failErr
+		}
+		return ctx.Err()
+	}
+
+	// 按顺序分发分片，pending 中是已经开始合成的分片的结果通道
+	// 正在合成和等待拼接的分片总数不超过 maxConcurrency，内存中只保留这个窗口内的音频
+	pending := make(chan chan ChanJoinVoice, g.maxConcurrency-1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(pending)
+		for i, v := range textList {
+			ch := make(chan ChanJoinVoice, 1)
+			select {
+			case pending <- ch:
+			case <-ctx.Done():
+				return
+			}
+
+			newMap := internal.DeepCopyParams(params)
+			newMap["request"]["text"] = v
+
+			// 如果文本被拆开，则中间的连接停顿应该减小
+			if i != (len(textList) - 1) {
+				newMap["request"]["silence_duration"] = 50
+			}
+
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				res := g.workTextToJoinVoiceDisk(ctx, newMap, idx)
+				if res.Err != nil {
+					fail(res.Err)
+				}
+				ch <- res
+			}(i)
+		}
+	}()
+
+	// 按照顺序和音频格式拼接结果
+	joiner := g.newAudioJoiner(params, w)
+	joined := 0
+	for ch := range pending {
+		var wordRes ChanJoinVoice
+		select {
+		case wordRes = <-ch:
+		case <-ctx.Done():
+			return firstErr()
+		}
 		if wordRes.Err != nil {
-			return wordRes.Err
+			return firstErr()
+		}
+		if err := joiner.Append(wordRes.Audio); err != nil {
+			return fmt.Errorf("join audio chunk %d error: %w", wordRes.Index, err)
+		}
+		if result != nil {
+			result.add(textList[wordRes.Index], wordRes.Result)
 		}
-		resMap[wordRes.Index] = wordRes.Audio
+		joined++
 	}
-
-	// 按照顺序拼接结果
-	resAudio := []byte{}
-	for i, _ := range textList {
-		r, ok := resMap[i]
-		if !ok {
-			return errors.New("error in sequential splicing")
+	if joined != len(textList) {
+		if err := firstErr(); err != nil {
+			return err
 		}
-		resAudio = append(resAudio, r...)
+		return errors.New("error in sequential splicing")
 	}
-	return internal.WriteBytesToDisk(resAudio, outFile)
-
+	if err := joiner.Close(); err != nil {
+		return err
+	}
+	if result != nil {
+		var spans []internal.ChunkSpan
+		if s, ok := joiner.(*internal.SmoothJoiner); ok {
+			spans = s.Spans()
+		}
+		result.place(spans)
+	}
+	return nil
 }
 
-func (g *GoTTS) workTextToJoinVoiceDisk(params map[string]map[string]any, idx int, ch chan ChanJoinVoice) {
-	params["request"]["reqid"] = uuid.NewString()
-
-	resp, funcClose, err := g.TextToVoice(params)
-	defer funcClose()
-	if err != nil {
-		ch <- ChanJoinVoice{Index: idx, Err: fmt.Errorf("TextToVoice error: %w", err)}
-		return
+// TextToVoiceReader 文本转语音，返回按顺序拼接的音频流
+// 后台协程通过 [GoTTS.TextToJoinVoiceWriter] 写入管道，合成失败时 Read 返回对应的错误
+func (g *GoTTS) TextToVoiceReader(ctx context.Context, params map[string]map[string]any) (io.ReadCloser, error) {
+	if err := internal.CheckParams(params, checkParamsSSML, g.checkParamsVoice); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		ch <- ChanJoinVoice{Index: idx, Err: fmt.Errorf("ReadAll error: %w", err)}
-		return
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	go func() {
+		defer cancel()
+		pw.CloseWithError(g.TextToJoinVoiceWriter(ctx, params, pw))
+	}()
+	return &voiceReader{PipeReader: pr, cancel: cancel}, nil
+}
 
-	var rep Rep
-	if err := json.Unmarshal(respBody, &rep); err != nil {
-		ch <- ChanJoinVoice{Index: idx, Err: fmt.Errorf("JSON unmarshal error: %w", err)}
-		return
-	}
+// voiceReader 关闭时取消尚未完成的合成请求
+type voiceReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
 
-	audio, err := base64.StdEncoding.DecodeString(rep.Data)
+func (r *voiceReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// workTextToJoinVoiceDisk 合成单个分片
+func (g *GoTTS) workTextToJoinVoiceDisk(ctx context.Context, params map[string]map[string]any, idx int) ChanJoinVoice {
+	params["request"]["reqid"] = uuid.NewString()
+
+	result, err := g.synthesize(ctx, params)
 	if err != nil {
-		ch <- ChanJoinVoice{Index: idx, Err: fmt.Errorf("base64 decode error: %w", err)}
-		return
+		return ChanJoinVoice{Index: idx, Err: fmt.Errorf("TextToVoice error: %w", err)}
 	}
 
-	ch <- ChanJoinVoice{
-		Index: idx,
-		Audio: audio,
+	return ChanJoinVoice{
+		Index:  idx,
+		Audio:  result.Audio,
+		Result: result,
 	}
 }
@@ -0,0 +1,462 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+var testWavFormat = &internal.WavFormat{AudioFormat: 1, Channels: 1, SampleRate: 24000, ByteRate: 48000, BlockAlign: 2, BitsPerSample: 16}
+
+// newTestServer 启动 bytettstest 模拟服务，测试结束后关闭
+func newTestServer(t *testing.T) *bytettstest.Server {
+	srv := bytettstest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// requestPaths 模拟服务收到的请求路径
+func requestPaths(srv *bytettstest.Server) []string {
+	var paths []string
+	for _, r := range srv.Requests() {
+		paths = append(paths, r.Path)
+	}
+	return paths
+}
+
+func newOfflineTTS(t *testing.T, opts ...Option) GoTTSInter {
+	opts = append([]Option{
+		WithAppId("appid"),
+		WithCluster("cluster"),
+		WithToken("token"),
+	}, opts...)
+	tts, err := NewGoTTS(context.TODO(), opts...)
+	if err != nil {
+		t.Fatalf("NewGoTTS err = %v", err)
+	}
+	return tts
+}
+
+func createTempFile(t *testing.T) *os.File {
+	outFile, err := os.Create(filepath.Join(t.TempDir(), "voice.mp3"))
+	if err != nil {
+		t.Fatalf("create file err = %v", err)
+	}
+	t.Cleanup(func() { outFile.Close() })
+	return outFile
+}
+
+func readTempFile(t *testing.T, outFile *os.File) []byte {
+	b, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		t.Fatalf("read file err = %v", err)
+	}
+	return b
+}
+
+func TestWithBaseURLTextToVoiceDisk(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL+"/"))
+
+	req := newTestRequest()
+	outFile := createTempFile(t)
+	if err := tts.TextToVoiceDiskRequest(req, outFile); err != nil {
+		t.Fatalf("TextToVoiceDiskRequest err = %v", err)
+	}
+	if got := readTempFile(t, outFile); string(got) != req.Request.Text {
+		t.Errorf("audio = %q, want %q", got, req.Request.Text)
+	}
+}
+
+func TestWithBaseURLTextToJoinVoiceDisk(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 100))
+	outFile := createTempFile(t)
+	if err := tts.TextToJoinVoiceDiskRequest(req, outFile); err != nil {
+		t.Fatalf("TextToJoinVoiceDiskRequest err = %v", err)
+	}
+	if got := readTempFile(t, outFile); string(got) != req.Request.Text {
+		t.Errorf("joined audio length = %d, want %d", len(got), len(req.Request.Text))
+	}
+	if n := len(requestPaths(srv)); n < 2 {
+		t.Errorf("requests = %d, want chunked requests", n)
+	}
+}
+
+func TestTextToJoinVoiceDiskWav(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Audio.Encoding = "wav"
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 100))
+	outFile := createTempFile(t)
+	if err := tts.TextToJoinVoiceDiskRequest(req, outFile); err != nil {
+		t.Fatalf("TextToJoinVoiceDiskRequest err = %v", err)
+	}
+
+	// 合并后只有一个头部，data 长度为全部分片之和
+	format, data, err := internal.ParseWav(readTempFile(t, outFile))
+	if err != nil {
+		t.Fatalf("ParseWav err = %v", err)
+	}
+	if !format.Equal(testWavFormat) {
+		t.Errorf("format = %s, want %s", format, testWavFormat)
+	}
+	if string(data) != req.Request.Text {
+		t.Errorf("joined data length = %d, want %d", len(data), len(req.Request.Text))
+	}
+}
+
+func TestWithBaseURLLongText(t *testing.T) {
+	for _, emotion := range []bool{false, true} {
+		srv := newTestServer(t)
+		opts := []Option{WithBaseURL(srv.URL)}
+		wantSubmit, wantQuery := apiLongTts, apiLongTtsQuery
+		if emotion {
+			opts = append(opts, WithEmotion())
+			wantSubmit, wantQuery = apiLongEmotionTts, apiLongEmotionTtsQuery
+		}
+		tts := newOfflineTTS(t, opts...)
+
+		created, err := tts.LongTextToVoiceCreate(map[string]any{
+			"text":       "昏黑的夜色中，月光如水洒落。",
+			"format":     "mp3",
+			"voice_type": "BV406_V2_streaming",
+		})
+		if err != nil {
+			t.Fatalf("LongTextToVoiceCreate err = %v", err)
+		}
+		res, err := tts.LongTextToVoiceId(created.TaskId)
+		if err != nil {
+			t.Fatalf("LongTextToVoiceId err = %v", err)
+		}
+		if res.TaskId != "task-1" {
+			t.Errorf("task id = %q", res.TaskId)
+		}
+
+		paths := requestPaths(srv)
+		if len(paths) != 2 || paths[0] != wantSubmit || paths[1] != wantQuery {
+			t.Errorf("emotion=%v paths = %v", emotion, paths)
+		}
+	}
+}
+
+func TestWithEndpoints(t *testing.T) {
+	srv := newTestServer(t)
+	other := newTestServer(t)
+
+	// 与选项顺序无关
+	tts := newOfflineTTS(t,
+		WithEndpoints(Endpoints{LongTts: other.URL + bytettstest.PathSubmit}),
+		WithBaseURL(srv.URL),
+	)
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	if paths := requestPaths(other); len(paths) != 1 || paths[0] != apiLongTts {
+		t.Errorf("other paths = %v", paths)
+	}
+
+	// 未覆盖的接口仍然使用 WithBaseURL 的地址，任务在另一个服务上创建，查询不到
+	if _, err := tts.LongTextToVoiceId(created.TaskId); err == nil {
+		t.Fatal("expected task not found from the base url server")
+	}
+	if paths := requestPaths(srv); len(paths) != 1 || paths[0] != apiLongTtsQuery {
+		t.Errorf("paths = %v", paths)
+	}
+}
+
+// countingTransport 统计经过的请求数
+type countingTransport struct {
+	mu    sync.Mutex
+	count int
+	next  http.RoundTripper
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+	return c.next.RoundTrip(r)
+}
+
+func TestWithTransport(t *testing.T) {
+	srv := newTestServer(t)
+	rt := &countingTransport{next: http.DefaultTransport}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithTransport(rt))
+
+	if err := tts.TextToVoiceDiskRequest(newTestRequest(), createTempFile(t)); err != nil {
+		t.Fatalf("TextToVoiceDiskRequest err = %v", err)
+	}
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	if _, err := tts.LongTextToVoiceId(created.TaskId); err != nil {
+		t.Fatalf("LongTextToVoiceId err = %v", err)
+	}
+	if rt.count != 3 {
+		t.Errorf("round trips = %d, want 3", rt.count)
+	}
+}
+
+func TestWithTransportOptionOrder(t *testing.T) {
+	srv := newTestServer(t)
+	client := &http.Client{Timeout: time.Second * 5}
+	for _, opts := range [][]Option{
+		{WithTransport(&countingTransport{next: http.DefaultTransport}), WithHTTPClient(client)},
+		{WithHTTPClient(client), WithTransport(&countingTransport{next: http.DefaultTransport})},
+	} {
+		g := newOfflineTTS(t, append(opts, WithBaseURL(srv.URL))...).(*GoTTS)
+		if _, ok := g.httpClient.Transport.(*countingTransport); !ok || g.httpClient.Timeout != client.Timeout {
+			t.Errorf("client = %+v, want transport applied to the custom client", g.httpClient)
+		}
+	}
+	if client.Transport != nil {
+		t.Errorf("caller client was modified, transport = %v", client.Transport)
+	}
+}
+
+func TestHTTPClientConnectionReuse(t *testing.T) {
+	srv := newTestServer(t)
+	client := &http.Client{Timeout: time.Second * 5}
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithHTTPClient(client))
+	for i := 0; i < 3; i++ {
+		if err := tts.TextToVoiceDiskRequest(newTestRequest(), createTempFile(t)); err != nil {
+			t.Fatalf("TextToVoiceDiskRequest err = %v", err)
+		}
+	}
+
+	if conns := srv.Conns(); conns != 1 {
+		t.Errorf("new connections = %d, want 1", conns)
+	}
+	if client.Timeout != time.Second*5 {
+		t.Errorf("caller client was modified, timeout = %v", client.Timeout)
+	}
+}
+
+func TestTextToVoiceContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	start := time.Now()
+	err := tts.TextToVoiceDiskRequestContext(ctx, newTestRequest(), createTempFile(t))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("request was not cancelled in time")
+	}
+}
+
+func TestTextToJoinVoiceDiskContextCancel(t *testing.T) {
+	var mu sync.Mutex
+	inflight := 0
+	cancelled := 0
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 读完请求体后服务端才能感知客户端断开
+		_, _ = io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		inflight++
+		mu.Unlock()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			mu.Lock()
+			cancelled++
+			mu.Unlock()
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		cancel()
+	}()
+
+	req := newTestRequest()
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 300))
+	err := tts.TextToJoinVoiceDiskRequestContext(ctx, req, createTempFile(t))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+
+	// 服务端感知到的取消需要一点时间
+	deadline := time.Now().Add(time.Second * 2)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := inflight > 0 && cancelled == inflight
+		mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t.Errorf("cancelled %d of %d in-flight chunk requests", cancelled, inflight)
+}
+
+func TestTextToVoiceAPIError(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		target error
+	}{
+		{http.StatusOK, `{"reqid":"r1","code":3011,"message":"invalid text"}`, ErrInvalidText},
+		{http.StatusOK, `{"reqid":"r1","code":3000,"data":""}`, ErrEmptyAudio},
+		{http.StatusUnauthorized, `{"reqid":"r1","code":3001,"message":"invalid token"}`, ErrInvalidParam},
+		{http.StatusUnauthorized, `Unauthorized`, ErrAuth},
+		{http.StatusServiceUnavailable, ``, ErrServerBusy},
+	}
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			_, _ = io.WriteString(w, c.body)
+		}))
+		tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+		err := tts.TextToVoiceDiskRequest(newTestRequest(), createTempFile(t))
+		if !errors.Is(err, c.target) {
+			t.Errorf("status=%d body=%s err = %v, want %v", c.status, c.body, err, c.target)
+		}
+		err = tts.TextToJoinVoiceDiskRequest(newTestRequest(), createTempFile(t))
+		if !errors.Is(err, c.target) {
+			t.Errorf("join status=%d body=%s err = %v, want %v", c.status, c.body, err, c.target)
+		}
+		srv.Close()
+	}
+}
+
+func TestLongTextAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"reqid":"r1","code":42900,"message":"too many requests"}`)
+	}))
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	_, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 42900 || apiErr.ReqID != "r1" {
+		t.Fatalf("err = %v, want APIError 42900", err)
+	}
+	if !errors.Is(err, ErrQuota) || !errors.Is(err, ErrRetryable) {
+		t.Errorf("err = %v should be quota and retryable", err)
+	}
+
+	_, err = tts.LongTextToVoiceId("task-1")
+	if !errors.Is(err, ErrQuota) {
+		t.Errorf("query err = %v, want ErrQuota", err)
+	}
+}
+
+func TestTextToJoinVoiceDiskMaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inflight, peak := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		mu.Lock()
+		inflight++
+		if inflight > peak {
+			peak = inflight
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond * 20)
+		mu.Lock()
+		inflight--
+		mu.Unlock()
+
+		text, _ := params["request"]["text"].(string)
+		_ = json.NewEncoder(w).Encode(Rep{Code: 3000, Data: base64.StdEncoding.EncodeToString([]byte(text))})
+	}))
+	defer srv.Close()
+
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithMaxConcurrency(2))
+	req := newTestRequest()
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 300))
+	outFile := createTempFile(t)
+	if err := tts.TextToJoinVoiceDiskRequest(req, outFile); err != nil {
+		t.Fatalf("TextToJoinVoiceDiskRequest err = %v", err)
+	}
+	if got := readTempFile(t, outFile); string(got) != req.Request.Text {
+		t.Errorf("joined audio length = %d, want %d", len(got), len(req.Request.Text))
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+}
+
+func TestTextToJoinVoiceDiskFailFast(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		// 第一个分片立即失败，其余分片等待取消
+		if n == 1 {
+			_ = json.NewEncoder(w).Encode(Rep{Code: 3010, Message: "text too long"})
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second * 5):
+		}
+	}))
+	defer srv.Close()
+
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL), WithMaxConcurrency(3))
+	req := newTestRequest()
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 300))
+
+	start := time.Now()
+	err := tts.TextToJoinVoiceDiskRequest(req, createTempFile(t))
+	if !errors.Is(err, ErrInvalidText) {
+		t.Fatalf("err = %v, want ErrInvalidText", err)
+	}
+	if d := time.Since(start); d > time.Second*2 {
+		t.Errorf("returned after %v, want fail fast", d)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests > 3 {
+		t.Errorf("requests = %d, want <= 3", requests)
+	}
+}
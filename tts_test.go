@@ -25,7 +25,15 @@ func TestEnv(t *testing.T) {
 	//fmt.Println(appId, token, cluster)
 }
 
+// skipWithoutEnv 未配置字节凭证时跳过需要访问线上服务的测试
+func skipWithoutEnv(t *testing.T) {
+	if appId == "" || token == "" || cluster == "" {
+		t.Skip("byte_appId / byte_token / byte_cluster not set, skip live test")
+	}
+}
+
 func TestTextToVoiceDisk(t *testing.T) {
+	skipWithoutEnv(t)
 	tts, err := NewGoTTS(
 		context.TODO(),
 		WithAppId(appId),
@@ -72,6 +80,7 @@ func TestTextToVoiceDisk(t *testing.T) {
 }
 
 func TestTextToVoice(t *testing.T) {
+	skipWithoutEnv(t)
 	tts, err := NewGoTTS(
 		context.TODO(),
 		WithAppId(appId),
@@ -136,7 +145,32 @@ func TestTextToVoice(t *testing.T) {
 	}
 }
 
+func TestSynthesize(t *testing.T) {
+	skipWithoutEnv(t)
+	tts, err := NewGoTTS(
+		context.TODO(),
+		WithAppId(appId),
+		WithCluster(cluster),
+		WithToken(token),
+	)
+	if err != nil {
+		log.Fatalf("初始化失败，err:%v", err)
+	}
+
+	req := &SynthesisRequest{
+		User:    UserConfig{Uid: "uid"},
+		Audio:   AudioConfig{VoiceType: "BV406_V2_streaming", Encoding: "mp3"},
+		Request: RequestConfig{Text: "中华兴盛，辛有斌哥。How are you"},
+	}
+	result, err := tts.Synthesize(context.TODO(), req)
+	if err != nil {
+		log.Fatalf("文本转语音失败，err:%v", err)
+	}
+	fmt.Printf("reqid: %s, duration: %v, size: %d \n", result.ReqID, result.Duration, len(result.Audio))
+}
+
 func TestLongTextToVoiceCreate(t *testing.T) {
+	skipWithoutEnv(t)
 	var params = make(map[string]any)
 	params["text"] = "昏黑 hūn hēi 昏黑的夜色中 昏黑的夜色中，月光如水洒落，静谧而神秘，仿佛将世界染上一抹深邃的诗意。"
 	params["format"] = "mp3"
@@ -161,6 +195,7 @@ func TestLongTextToVoiceCreate(t *testing.T) {
 }
 
 func TestLongTextToVoiceId(t *testing.T) {
+	skipWithoutEnv(t)
 	tts, err := NewGoTTS(
 		context.TODO(),
 		WithAppId(appId),
@@ -184,12 +219,16 @@ func TestLongTextToVoiceId(t *testing.T) {
 	}
 	defer outFile.Close()
 
-	internal.DownloadToDisk(res.AudioUrl, outFile)
+	err = tts.DownloadLongTextAudio(context.TODO(), res, outFile)
+	if err != nil {
+		log.Fatalf("下载长文本语音失败，err:%v", err)
+	}
 
 	fmt.Printf("%v \n", res)
 }
 
 func TestTextToJoinVoiceDisk(t *testing.T) {
+	skipWithoutEnv(t)
 	tts, err := NewGoTTS(
 		context.TODO(),
 		WithAppId(appId),
@@ -1,5 +1,10 @@
 package go_byte_tts
 
+import (
+	"fmt"
+	"time"
+)
+
 type App struct {
 	Appid   string `json:"appid"`
 	Token   string `json:"token"`
@@ -7,36 +12,79 @@ type App struct {
 }
 
 type Rep struct {
-	ReqID     string `json:"reqid"`
-	Code      int    `json:"code"`
-	Message   string `json:"Message"`
-	Operation string `json:"operation"`
-	Sequence  int    `json:"sequence"`
-	Data      string `json:"data"`
+	ReqID     string       `json:"reqid"`
+	Code      int          `json:"code"`
+	Message   string       `json:"Message"`
+	Operation string       `json:"operation"`
+	Sequence  int          `json:"sequence"`
+	Data      string       `json:"data"`
+	Addition  *RepAddition `json:"addition,omitempty"`
+}
+
+// RepAddition 短文本合成结果的附加信息
+type RepAddition struct {
+	Duration string `json:"duration,omitempty"` // 音频时长，单位毫秒
+	Frontend string `json:"frontend,omitempty"` // 前端信息（JSON字符串），请求设置 with_frontend 时返回
 }
 
 type TtsAsyncRep struct {
-	Reqid      string `json:"reqid"`
-	Code       int    `json:"code"`
-	Message    string `json:"message"`
-	TaskId     string `json:"task_id"`
-	TaskStatus int    `json:"task_status"`
-	TextLength int    `json:"text_length"`
+	Reqid      string     `json:"reqid"`
+	Code       int        `json:"code"`
+	Message    string     `json:"message"`
+	TaskId     string     `json:"task_id"`
+	TaskStatus TaskStatus `json:"task_status"`
+	TextLength int        `json:"text_length"`
 }
 
 type TtsAsyncQueryRep struct {
-	Reqid         string `json:"reqid"`
-	Code          int    `json:"code"`
-	Message       string `json:"message"`
-	AudioUrl      string `json:"audio_url"`
-	TaskId        string `json:"task_id"`
-	TaskStatus    int    `json:"task_status"`
-	TextLength    int    `json:"text_length"`
-	UrlExpireTime int    `json:"url_expire_time"`
+	Reqid         string     `json:"reqid"`
+	Code          int        `json:"code"`
+	Message       string     `json:"message"`
+	AudioUrl      string     `json:"audio_url"`
+	TaskId        string     `json:"task_id"`
+	TaskStatus    TaskStatus `json:"task_status"`
+	TextLength    int        `json:"text_length"`
+	UrlExpireTime int        `json:"url_expire_time"` // 音频URL过期时间，Unix时间戳（秒）
+}
+
+// ExpiresAt 音频URL的过期时间，未返回过期时间时为零值
+func (r *TtsAsyncQueryRep) ExpiresAt() time.Time {
+	if r.UrlExpireTime <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(r.UrlExpireTime), 0)
+}
+
+// TaskStatus 长文本任务状态
+type TaskStatus int
+
+const (
+	TaskStatusRunning TaskStatus = 0 // 合成中
+	TaskStatusSuccess TaskStatus = 1 // 合成成功
+	TaskStatusFailure TaskStatus = 2 // 合成失败
+)
+
+// IsTerminal 任务是否已经结束，结束后状态不会再变化
+func (s TaskStatus) IsTerminal() bool {
+	return s == TaskStatusSuccess || s == TaskStatusFailure
+}
+
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskStatusRunning:
+		return "running"
+	case TaskStatusSuccess:
+		return "success"
+	case TaskStatusFailure:
+		return "failure"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
 }
 
 type ChanJoinVoice struct {
-	Index int
-	Audio []byte
-	Err   error
+	Index  int
+	Audio  []byte
+	Result *SynthesisResult // 分片的合成结果，失败时为 nil
+	Err    error
 }
@@ -0,0 +1,46 @@
+package go_byte_tts
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTaskStatus(t *testing.T) {
+	cases := []struct {
+		status   TaskStatus
+		terminal bool
+		str      string
+	}{
+		{TaskStatusRunning, false, "running"},
+		{TaskStatusSuccess, true, "success"},
+		{TaskStatusFailure, true, "failure"},
+		{TaskStatus(9), false, "unknown(9)"},
+	}
+	for _, c := range cases {
+		if c.status.IsTerminal() != c.terminal {
+			t.Errorf("%v.IsTerminal() = %v", c.status, !c.terminal)
+		}
+		if c.status.String() != c.str {
+			t.Errorf("String() = %q, want %q", c.status.String(), c.str)
+		}
+	}
+}
+
+func TestTtsAsyncQueryRepDecode(t *testing.T) {
+	body := `{"reqid":"r1","task_id":"t1","task_status":1,"audio_url":"https://x/a.mp3","url_expire_time":1700003600}`
+	var rep TtsAsyncQueryRep
+	if err := json.Unmarshal([]byte(body), &rep); err != nil {
+		t.Fatalf("Unmarshal err = %v", err)
+	}
+	if rep.TaskStatus != TaskStatusSuccess || !rep.TaskStatus.IsTerminal() {
+		t.Errorf("task status = %v", rep.TaskStatus)
+	}
+	if !rep.ExpiresAt().Equal(time.Unix(1700003600, 0)) {
+		t.Errorf("ExpiresAt() = %v", rep.ExpiresAt())
+	}
+
+	if !(&TtsAsyncQueryRep{}).ExpiresAt().IsZero() {
+		t.Error("ExpiresAt() should be zero without url_expire_time")
+	}
+}
@@ -0,0 +1,176 @@
+package go_byte_tts
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jefferyjob/go-easy-utils/anyUtil"
+)
+
+//go:embed voices.json
+var voiceCatalogJSON []byte
+
+var (
+	// ErrUnknownVoice 音色不在内置的音色目录中
+	ErrUnknownVoice = errors.New("tts: unknown voice_type")
+	// ErrUnsupportedEmotion 音色不支持该情感
+	ErrUnsupportedEmotion = errors.New("tts: emotion not supported by voice")
+	// ErrUnsupportedRate 音色不支持该采样率
+	ErrUnsupportedRate = errors.New("tts: rate not supported by voice")
+)
+
+// Voice 音色信息
+type Voice struct {
+	VoiceType   string   `json:"voice_type"`         // 音色代号，即 audio.voice_type
+	Name        string   `json:"name"`               // 显示名称
+	Gender      string   `json:"gender"`             // 性别：female / male
+	Languages   []string `json:"languages"`          // 支持的语言，例如 zh / en / ja
+	Emotions    []string `json:"emotions,omitempty"` // 支持的情感，即 audio.emotion，为空时不支持情感
+	Streaming   bool     `json:"streaming"`          // 是否支持流式合成
+	SampleRates []int    `json:"sample_rates"`       // 支持的采样率
+	Clusters    []string `json:"clusters"`           // 音色所属的业务集群
+}
+
+// 所有音色都接受的通用情感
+var generalEmotions = map[string]bool{"": true, "通用": true, "general": true}
+
+// SupportsEmotion 音色是否支持该情感，空字符串和通用情感总是支持
+func (v Voice) SupportsEmotion(emotion string) bool {
+	return generalEmotions[emotion] || containsString(v.Emotions, emotion)
+}
+
+// SupportsLanguage 音色是否支持该语言
+func (v Voice) SupportsLanguage(language string) bool {
+	for _, l := range v.Languages {
+		if strings.EqualFold(l, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsRate 音色是否支持该采样率，0 表示使用默认采样率
+func (v Voice) SupportsRate(rate int) bool {
+	if rate == 0 {
+		return true
+	}
+	for _, r := range v.SampleRates {
+		if r == rate {
+			return true
+		}
+	}
+	return false
+}
+
+// InCluster 音色是否属于该业务集群
+func (v Voice) InCluster(cluster string) bool {
+	return containsString(v.Clusters, cluster)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// voiceCatalog 内置的音色目录
+type voiceCatalog struct {
+	Version string  `json:"version"`
+	Voices  []Voice `json:"voices"`
+
+	byType map[string]int
+}
+
+var catalog = loadVoiceCatalog()
+
+func loadVoiceCatalog() *voiceCatalog {
+	c := &voiceCatalog{}
+	if err := json.Unmarshal(voiceCatalogJSON, c); err != nil {
+		panic(fmt.Sprintf("go_byte_tts: invalid voices.json: %v", err))
+	}
+	sort.Slice(c.Voices, func(i, j int) bool { return c.Voices[i].VoiceType < c.Voices[j].VoiceType })
+	c.byType = make(map[string]int, len(c.Voices))
+	for i, v := range c.Voices {
+		c.byType[v.VoiceType] = i
+	}
+	return c
+}
+
+// VoiceCatalogVersion 内置音色目录的版本
+func VoiceCatalogVersion() string {
+	return catalog.Version
+}
+
+// Voices 返回内置音色目录中的全部音色，按音色代号排序，修改返回值不影响目录
+// 目录来自火山引擎文档，新上线的音色可能不在其中，参见 [WithStrictVoices]
+func Voices() []Voice {
+	voices := make([]Voice, len(catalog.Voices))
+	for i, v := range catalog.Voices {
+		voices[i] = v.clone()
+	}
+	return voices
+}
+
+// LookupVoice 按音色代号查找音色
+func LookupVoice(voiceType string) (Voice, bool) {
+	i, ok := catalog.byType[voiceType]
+	if !ok {
+		return Voice{}, false
+	}
+	return catalog.Voices[i].clone(), true
+}
+
+func (v Voice) clone() Voice {
+	v.Languages = append([]string(nil), v.Languages...)
+	v.Emotions = append([]string(nil), v.Emotions...)
+	v.SampleRates = append([]int(nil), v.SampleRates...)
+	v.Clusters = append([]string(nil), v.Clusters...)
+	return v
+}
+
+// CheckVoice 按音色目录校验音色、情感和采样率的组合
+// 音色不在目录中时返回 [ErrUnknownVoice]，调用方可以选择忽略
+func CheckVoice(voiceType, emotion string, rate int) error {
+	v, ok := LookupVoice(voiceType)
+	if !ok {
+		return fmt.Errorf("audio.voice_type %q: %w", voiceType, ErrUnknownVoice)
+	}
+	if !v.SupportsEmotion(emotion) {
+		return fmt.Errorf("audio.emotion %q for voice %s: %w", emotion, voiceType, ErrUnsupportedEmotion)
+	}
+	if !v.SupportsRate(rate) {
+		return fmt.Errorf("audio.rate %d for voice %s: %w", rate, voiceType, ErrUnsupportedRate)
+	}
+	return nil
+}
+
+// checkVoice 非严格模式下目录中没有的音色直接放行，由服务端校验
+func checkVoice(voiceType, emotion string, rate int, strict bool) error {
+	err := CheckVoice(voiceType, emotion, rate)
+	if !strict && errors.Is(err, ErrUnknownVoice) {
+		return nil
+	}
+	return err
+}
+
+// WithStrictVoices 拒绝音色目录中没有的音色，默认只校验目录中已有音色的情感和采样率
+func WithStrictVoices() Option {
+	return func(g *GoTTS) {
+		g.strictVoices = true
+	}
+}
+
+// checkParamsVoice 作为 [internal.CheckParams] 的附加检查，校验 map 参数中的音色
+func (g *GoTTS) checkParamsVoice(params map[string]map[string]any) error {
+	voiceType := anyUtil.AnyToStr(params["audio"]["voice_type"])
+	emotion := anyUtil.AnyToStr(params["audio"]["emotion"])
+	rate, _ := anyUtil.AnyToInt(params["audio"]["rate"])
+	return checkVoice(voiceType, emotion, rate, g.strictVoices)
+}
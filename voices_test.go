@@ -0,0 +1,116 @@
+package go_byte_tts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+func TestVoiceCatalog(t *testing.T) {
+	if VoiceCatalogVersion() == "" {
+		t.Error("catalog version should not be empty")
+	}
+	voices := Voices()
+	if len(voices) == 0 {
+		t.Fatal("catalog should not be empty")
+	}
+	seen := map[string]bool{}
+	for i, v := range voices {
+		if v.VoiceType == "" || v.Name == "" || len(v.Languages) == 0 || len(v.SampleRates) == 0 || len(v.Clusters) == 0 {
+			t.Errorf("incomplete voice %+v", v)
+		}
+		if seen[v.VoiceType] {
+			t.Errorf("duplicate voice %s", v.VoiceType)
+		}
+		seen[v.VoiceType] = true
+		if i > 0 && voices[i-1].VoiceType > v.VoiceType {
+			t.Errorf("voices are not sorted at %s", v.VoiceType)
+		}
+	}
+
+	// 修改返回值不影响目录
+	voices[0].Languages[0] = "changed"
+	if v, _ := LookupVoice(voices[0].VoiceType); v.Languages[0] == "changed" {
+		t.Error("Voices should return copies")
+	}
+}
+
+func TestLookupVoice(t *testing.T) {
+	v, ok := LookupVoice("BV700_streaming")
+	if !ok {
+		t.Fatal("BV700_streaming should be in catalog")
+	}
+	if !v.SupportsEmotion("happy") || v.SupportsEmotion("unknown") {
+		t.Errorf("emotions = %v", v.Emotions)
+	}
+	if !v.SupportsEmotion("") || !v.SupportsEmotion("通用") || !v.SupportsEmotion("general") {
+		t.Error("general emotion should always be supported")
+	}
+	if !v.SupportsLanguage("ZH") || !v.SupportsRate(0) || !v.SupportsRate(16000) || v.SupportsRate(44100) {
+		t.Errorf("voice = %+v", v)
+	}
+	if !v.InCluster("volcano_tts") {
+		t.Errorf("clusters = %v", v.Clusters)
+	}
+	if _, ok := LookupVoice("BV999_streaming"); ok {
+		t.Error("unknown voice should not be found")
+	}
+}
+
+func TestCheckVoice(t *testing.T) {
+	cases := []struct {
+		voice   string
+		emotion string
+		rate    int
+		want    error
+	}{
+		{"BV700_streaming", "happy", 24000, nil},
+		{"BV700_streaming", "通用", 0, nil},
+		{"BV002_streaming", "happy", 0, ErrUnsupportedEmotion},
+		{"BV700_streaming", "", 44100, ErrUnsupportedRate},
+		{"BV999_streaming", "", 0, ErrUnknownVoice},
+	}
+	for _, c := range cases {
+		err := CheckVoice(c.voice, c.emotion, c.rate)
+		if !errors.Is(err, c.want) || (c.want == nil && err != nil) {
+			t.Errorf("CheckVoice(%s, %q, %d) = %v, want %v", c.voice, c.emotion, c.rate, err, c.want)
+		}
+	}
+}
+
+func TestStrictVoices(t *testing.T) {
+	srv := bytettstest.NewServer()
+	defer srv.Close()
+
+	req := newTestRequest()
+	req.Audio.VoiceType = "BV999_streaming"
+	params, err := req.Params()
+	if err != nil {
+		t.Fatalf("Params() err = %v", err)
+	}
+
+	// 默认放行目录中没有的音色
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+	if _, _, err := tts.TextToVoiceContext(context.Background(), params); err != nil {
+		t.Errorf("unknown voice should be allowed by default, err = %v", err)
+	}
+
+	strict := newOfflineTTS(t, WithBaseURL(srv.URL), WithStrictVoices())
+	_, _, err = strict.TextToVoiceContext(context.Background(), params)
+	if !errors.Is(err, ErrUnknownVoice) {
+		t.Errorf("err = %v, want ErrUnknownVoice", err)
+	}
+
+	// 不支持的情感在发送前返回错误
+	params["audio"]["voice_type"] = "BV002_streaming"
+	params["audio"]["emotion"] = "happy"
+	_, _, err = tts.TextToVoiceContext(context.Background(), params)
+	if !errors.Is(err, ErrUnsupportedEmotion) {
+		t.Errorf("err = %v, want ErrUnsupportedEmotion", err)
+	}
+	if n := len(srv.RequestsTo(bytettstest.PathTTS)); n != 1 {
+		t.Errorf("requests = %d, invalid voices should not be sent", n)
+	}
+}
@@ -0,0 +1,122 @@
+package go_byte_tts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	defaultPollInterval   = time.Second
+	defaultMaxInterval    = time.Second * 10
+	defaultPollMultiplier = 1.5
+)
+
+// WaitOptions 等待长文本任务完成的配置，零值使用默认配置
+type WaitOptions struct {
+	PollInterval time.Duration               // 首次查询前的等待时间，默认1秒
+	MaxInterval  time.Duration               // 查询间隔上限，默认10秒
+	Multiplier   float64                     // 每次查询间隔的倍数，默认1.5，小于1时按1处理
+	Timeout      time.Duration               // 整体超时时间，为0时只受 ctx 控制
+	OnProgress   func(rep *TtsAsyncQueryRep) // 每次查询成功后的回调，可用于展示进度
+}
+
+// TaskFailedError 长文本任务合成失败
+type TaskFailedError struct {
+	TaskId string            // 任务ID
+	Result *TtsAsyncQueryRep // 最后一次查询结果
+}
+
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("tts async task %s failed: code=%d, message=%s", e.TaskId, e.Result.Code, e.Result.Message)
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultPollInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultMaxInterval
+	}
+	if o.MaxInterval < o.PollInterval {
+		o.MaxInterval = o.PollInterval
+	}
+	if o.Multiplier == 0 {
+		o.Multiplier = defaultPollMultiplier
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1
+	}
+	return o
+}
+
+// WaitForLongTextTask 轮询长文本任务直到合成结束
+// 合成成功返回最后一次查询结果，合成失败返回 *TaskFailedError
+// 查询时遇到的可重试错误不会中断轮询，直到超时
+func (g *GoTTS) WaitForLongTextTask(ctx context.Context, taskId string, opts WaitOptions) (*TtsAsyncQueryRep, error) {
+	opts = opts.withDefaults()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	interval := opts.PollInterval
+	var lastErr error
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if lastErr != nil {
+				return nil, fmt.Errorf("wait for task %s: %v: %w", taskId, lastErr, ctx.Err())
+			}
+			return nil, fmt.Errorf("wait for task %s: %w", taskId, ctx.Err())
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * opts.Multiplier)
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+
+		rep, err := g.LongTextToVoiceIdContext(ctx, taskId)
+		if err != nil {
+			if ctx.Err() == nil && g.retryPolicy.retryable(err) {
+				lastErr = err
+				continue
+			}
+			return nil, err
+		}
+		lastErr = nil
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(rep)
+		}
+		switch rep.TaskStatus {
+		case TaskStatusSuccess:
+			return rep, nil
+		case TaskStatusFailure:
+			return nil, &TaskFailedError{TaskId: taskId, Result: rep}
+		}
+	}
+}
+
+// LongTextToVoiceDownload 创建长文本任务，等待合成完成后将音频下载写入 w
+func (g *GoTTS) LongTextToVoiceDownload(ctx context.Context, params map[string]any, w io.Writer, opts WaitOptions) (*TtsAsyncQueryRep, error) {
+	created, err := g.LongTextToVoiceCreateContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := g.WaitForLongTextTask(ctx, created.TaskId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.DownloadLongTextAudio(ctx, result, w); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/bytettstest"
+)
+
+// submitTask 在模拟服务上按脚本创建长文本任务，返回任务标识
+func submitTask(t *testing.T, tts GoTTSInter, srv *bytettstest.Server, task bytettstest.Task) string {
+	srv.EnqueueTask(task)
+	created, err := tts.LongTextToVoiceCreate(map[string]any{"text": "文本"})
+	if err != nil {
+		t.Fatalf("LongTextToVoiceCreate err = %v", err)
+	}
+	return created.TaskId
+}
+
+func fastWaitOptions() WaitOptions {
+	return WaitOptions{PollInterval: time.Millisecond, MaxInterval: time.Millisecond * 5}
+}
+
+func TestWaitForLongTextTask(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL)).(*GoTTS)
+	running := bytettstest.TaskRunning
+	taskId := submitTask(t, tts, srv, bytettstest.Task{Statuses: []int{running, running, running, bytettstest.TaskSuccess}})
+
+	var progress []TaskStatus
+	opts := fastWaitOptions()
+	opts.OnProgress = func(rep *TtsAsyncQueryRep) {
+		progress = append(progress, rep.TaskStatus)
+	}
+	res, err := tts.WaitForLongTextTask(context.Background(), taskId, opts)
+	if err != nil {
+		t.Fatalf("WaitForLongTextTask err = %v", err)
+	}
+	if res.TaskStatus != TaskStatusSuccess || res.AudioUrl == "" {
+		t.Errorf("result = %+v", res)
+	}
+	if len(progress) != 4 {
+		t.Errorf("progress callbacks = %v, want 4", progress)
+	}
+}
+
+func TestWaitForLongTextTaskFailure(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL)).(*GoTTS)
+	taskId := submitTask(t, tts, srv, bytettstest.Task{
+		Statuses: []int{bytettstest.TaskRunning, bytettstest.TaskFailure},
+		Message:  "synthesis failed",
+	})
+
+	_, err := tts.WaitForLongTextTask(context.Background(), taskId, fastWaitOptions())
+	var failed *TaskFailedError
+	if !errors.As(err, &failed) {
+		t.Fatalf("err = %v, want TaskFailedError", err)
+	}
+	if failed.TaskId != taskId || failed.Result.Message != "synthesis failed" {
+		t.Errorf("failed = %+v", failed)
+	}
+}
+
+func TestWaitForLongTextTaskTimeout(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL)).(*GoTTS)
+	taskId := submitTask(t, tts, srv, bytettstest.Task{Statuses: []int{bytettstest.TaskRunning}})
+
+	opts := fastWaitOptions()
+	opts.Timeout = time.Millisecond * 50
+	_, err := tts.WaitForLongTextTask(context.Background(), taskId, opts)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestLongTextToVoiceDownload(t *testing.T) {
+	srv := newTestServer(t)
+	srv.EnqueueTask(bytettstest.Task{
+		Statuses: []int{bytettstest.TaskRunning, bytettstest.TaskRunning, bytettstest.TaskSuccess},
+		Audio:    []byte("long audio"),
+	})
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	var buf bytes.Buffer
+	res, err := tts.LongTextToVoiceDownload(context.Background(), map[string]any{"text": "文本"}, &buf, fastWaitOptions())
+	if err != nil {
+		t.Fatalf("LongTextToVoiceDownload err = %v", err)
+	}
+	if res.TaskId != "task-1" {
+		t.Errorf("task id = %q", res.TaskId)
+	}
+	if buf.String() != "long audio" {
+		t.Errorf("audio = %q", buf.String())
+	}
+}
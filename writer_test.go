@@ -0,0 +1,126 @@
+package go_byte_tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zmexing/go-byte-tts/internal"
+)
+
+func TestTextToVoiceWriter(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	var buf bytes.Buffer
+	if err := tts.TextToVoiceRequestWriter(context.Background(), req, &buf); err != nil {
+		t.Fatalf("TextToVoiceRequestWriter err = %v", err)
+	}
+	if buf.String() != req.Request.Text {
+		t.Errorf("audio = %q, want %q", buf.String(), req.Request.Text)
+	}
+
+	// 写入哈希
+	h := sha256.New()
+	params, _ := req.Params()
+	if err := tts.TextToVoiceWriter(context.Background(), params, h); err != nil {
+		t.Fatalf("TextToVoiceWriter err = %v", err)
+	}
+	if want := sha256.Sum256([]byte(req.Request.Text)); !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Error("hash mismatch")
+	}
+}
+
+func TestTextToJoinVoiceWriterWav(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Audio.Encoding = "wav"
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 100))
+
+	// bytes.Buffer 不支持 Seek，合并后的头部在最后写入
+	var buf bytes.Buffer
+	if err := tts.TextToJoinVoiceRequestWriter(context.Background(), req, &buf); err != nil {
+		t.Fatalf("TextToJoinVoiceRequestWriter err = %v", err)
+	}
+	_, data, err := internal.ParseWav(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseWav err = %v", err)
+	}
+	if string(data) != req.Request.Text {
+		t.Errorf("joined data length = %d, want %d", len(data), len(req.Request.Text))
+	}
+}
+
+func TestTextToVoiceReader(t *testing.T) {
+	srv := newTestServer(t)
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	req := newTestRequest()
+	req.Request.Text = string(bytes.Repeat([]byte("月光如水洒落，静谧而神秘。"), 100))
+	params, _ := req.Params()
+
+	r, err := tts.TextToVoiceReader(context.Background(), params)
+	if err != nil {
+		t.Fatalf("TextToVoiceReader err = %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll err = %v", err)
+	}
+	if string(got) != req.Request.Text {
+		t.Errorf("audio length = %d, want %d", len(got), len(req.Request.Text))
+	}
+
+	if _, err := tts.TextToVoiceReader(context.Background(), map[string]map[string]any{}); err == nil {
+		t.Error("expected error for empty params")
+	}
+}
+
+func TestTextToVoiceReaderClose(t *testing.T) {
+	var mu sync.Mutex
+	cancelled := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			mu.Lock()
+			cancelled++
+			mu.Unlock()
+		case <-time.After(time.Second * 5):
+		}
+	}))
+	defer srv.Close()
+	tts := newOfflineTTS(t, WithBaseURL(srv.URL))
+
+	params, _ := newTestRequest().Params()
+	r, err := tts.TextToVoiceReader(context.Background(), params)
+	if err != nil {
+		t.Fatalf("TextToVoiceReader err = %v", err)
+	}
+	time.Sleep(time.Millisecond * 50)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close err = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second * 2)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := cancelled > 0
+		mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Error("Close did not cancel the in-flight request")
+}